@@ -59,7 +59,10 @@ func CompleteActivity(c *cli.Context) error {
 	// supports the full DataConverter API.
 	resultPayloads, _ := defaultDataConverter().ToPayloads(result)
 
-	frontendClient := cFactory.FrontendClient(c)
+	frontendClient, err := cFactory.FrontendClient(c)
+	if err != nil {
+		return err
+	}
 	_, err = frontendClient.RespondActivityTaskCompletedById(ctx, &workflowservice.RespondActivityTaskCompletedByIdRequest{
 		Namespace:  namespace,
 		WorkflowId: wid,
@@ -103,7 +106,10 @@ func FailActivity(c *cli.Context) error {
 	// supports the full DataConverter API.
 	detailsPayloads, _ := defaultDataConverter().ToPayloads(detail)
 
-	frontendClient := cFactory.FrontendClient(c)
+	frontendClient, err := cFactory.FrontendClient(c)
+	if err != nil {
+		return err
+	}
 	_, err = frontendClient.RespondActivityTaskFailedById(ctx, &workflowservice.RespondActivityTaskFailedByIdRequest{
 		Namespace:  namespace,
 		WorkflowId: wid,
@@ -51,7 +51,44 @@ func NewCliApp() *cli.App {
 	app.Name = "tctl"
 	app.Usage = "A command-line tool for Temporal users"
 	app.Version = "1.17.0-alpha.1"
-	app.Flags = []cli.Flag{
+	app.Flags = newGlobalFlags()
+	app.Commands = tctlCommands
+	app.Before = func(c *cli.Context) error {
+		if err := loadEnvironment(c); err != nil {
+			return err
+		}
+		return configureSDK(c)
+	}
+	app.After = stopPlugins
+	app.ExitErrHandler = handleError
+
+	// set builder if not customized
+	if cFactory == nil {
+		SetFactory(NewClientFactory())
+	}
+
+	if tctlConfig == nil {
+		var err error
+		if tctlConfig, err = config.NewTctlConfig(); err != nil {
+			fmt.Printf("unable to load tctl config: %v", err)
+			promptContinueWithoutConfig()
+		}
+	}
+	useDynamicCommands(app)
+	useCompletion(app)
+
+	return app
+}
+
+// newGlobalFlags returns the flags shared by all commands.
+// Except for --env, their values can also be stored in a config environment.
+func newGlobalFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    FlagEnv,
+			Usage:   "Config environment to use instead of the active one",
+			EnvVars: []string{"TEMPORAL_CLI_ENV"},
+		},
 		&cli.StringFlag{
 			Name:    FlagAddress,
 			Value:   "",
@@ -135,46 +172,94 @@ func NewCliApp() *cli.App {
 			Usage:   "Authorization header to set for requests to Codec Server",
 			EnvVars: []string{"TEMPORAL_CLI_CODEC_AUTH"},
 		},
+		&cli.StringSliceFlag{
+			Name:    FlagGRPCMeta,
+			Usage:   "gRPC metadata (headers) to send with every request, format: key=value. Can be passed multiple times",
+			EnvVars: []string{"TEMPORAL_CLI_GRPC_META"},
+		},
+		&cli.StringFlag{
+			Name:    FlagOAuthFlow,
+			Value:   "",
+			Usage:   fmt.Sprintf("OAuth2 flow used to obtain an access token: %v, %v", headersprovider.OAuthFlowClientCredentials, headersprovider.OAuthFlowDeviceCode),
+			EnvVars: []string{"TEMPORAL_CLI_OAUTH_FLOW"},
+		},
+		&cli.StringFlag{
+			Name:    FlagOAuthClientID,
+			Value:   "",
+			Usage:   "OAuth2 client ID",
+			EnvVars: []string{"TEMPORAL_CLI_OAUTH_CLIENT_ID"},
+		},
+		&cli.StringFlag{
+			Name:    FlagOAuthClientSecret,
+			Value:   "",
+			Usage:   "OAuth2 client secret",
+			EnvVars: []string{"TEMPORAL_CLI_OAUTH_CLIENT_SECRET"},
+		},
+		&cli.StringFlag{
+			Name:    FlagOAuthTokenURL,
+			Value:   "",
+			Usage:   "OAuth2 token endpoint",
+			EnvVars: []string{"TEMPORAL_CLI_OAUTH_TOKEN_URL"},
+		},
+		&cli.StringFlag{
+			Name:    FlagOAuthDeviceAuthURL,
+			Value:   "",
+			Usage:   "OAuth2 device authorization endpoint (device code flow only)",
+			EnvVars: []string{"TEMPORAL_CLI_OAUTH_DEVICE_AUTH_URL"},
+		},
+		&cli.StringFlag{
+			Name:    FlagOAuthScopes,
+			Value:   "",
+			Usage:   "Space or comma separated OAuth2 scopes to request",
+			EnvVars: []string{"TEMPORAL_CLI_OAUTH_SCOPES"},
+		},
+		&cli.StringFlag{
+			Name:    FlagOAuthAudience,
+			Value:   "",
+			Usage:   "OAuth2 audience to request the access token for",
+			EnvVars: []string{"TEMPORAL_CLI_OAUTH_AUDIENCE"},
+		},
 		&cli.StringFlag{
 			Name:  color.FlagColor,
 			Usage: fmt.Sprintf("when to use color: %v, %v, %v.", color.Auto, color.Always, color.Never),
 			Value: string(color.Auto),
 		},
 	}
-	app.Commands = tctlCommands
-	app.Before = configureSDK
-	app.After = stopPlugins
-	app.ExitErrHandler = handleError
-
-	// set builder if not customized
-	if cFactory == nil {
-		SetFactory(NewClientFactory())
-	}
-
-	if tctlConfig == nil {
-		var err error
-		if tctlConfig, err = config.NewTctlConfig(); err != nil {
-			fmt.Printf("unable to load tctl config: %v", err)
-			promptContinueWithoutConfig()
-		}
-	}
-	useDynamicCommands(app)
-
-	return app
 }
 
 func configureSDK(ctx *cli.Context) error {
-	endpoint := ctx.String(FlagCodecEndpoint)
+	endpoint := readFlagOrConfig(ctx, FlagCodecEndpoint)
 	if endpoint != "" {
-		dataconverter.SetRemoteEndpoint(
+		dataconverter.SetRemoteEndpointWithAuth(
 			endpoint,
-			ctx.String(FlagNamespace),
-			ctx.String(FlagCodecAuth),
+			readFlagOrConfig(ctx, FlagNamespace),
+			func() (string, error) {
+				return resolveFlagOrConfig(ctx, FlagCodecAuth)
+			},
 		)
 	}
 
-	if ctx.String(FlagAuth) != "" {
-		headersprovider.SetAuthorizationHeader(ctx.String(FlagAuth))
+	// credentials may be secret references, they are resolved once a request is made
+	if hasFlagOrConfig(ctx, FlagAuth) {
+		headersprovider.SetCurrent(headersprovider.NewLazyProvider(func() (headersprovider.HeadersProvider, error) {
+			auth, err := resolveFlagOrConfig(ctx, FlagAuth)
+			if err != nil {
+				return nil, fmt.Errorf("unable to resolve %s: %s", FlagAuth, err)
+			}
+
+			return headersprovider.NewAuthorizationHeaderProvider(auth), nil
+		}))
+	}
+
+	if hasFlagOrConfig(ctx, FlagOAuthFlow) {
+		headersprovider.SetCurrent(headersprovider.NewLazyProvider(func() (headersprovider.HeadersProvider, error) {
+			headersProvider, err := newOAuthHeadersProvider(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("unable to configure OAuth: %s", err)
+			}
+
+			return headersProvider, nil
+		}))
 	}
 
 	dcPlugin := ctx.String(FlagDataConverterPlugin)
@@ -197,9 +282,42 @@ func configureSDK(ctx *cli.Context) error {
 		headersprovider.SetCurrent(headersProvider)
 	}
 
+	grpcMeta, err := readGRPCMeta(ctx)
+	if err != nil {
+		return err
+	}
+	headersprovider.SetStaticHeaders(grpcMeta)
+
 	return nil
 }
 
+func newOAuthHeadersProvider(ctx *cli.Context) (headersprovider.HeadersProvider, error) {
+	cacheDir, err := config.Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	clientSecret, err := resolveFlagOrConfig(ctx, FlagOAuthClientSecret)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve %s: %s", FlagOAuthClientSecret, err)
+	}
+
+	return headersprovider.NewOAuthProvider(headersprovider.OAuthConfig{
+		Flow:          readFlagOrConfig(ctx, FlagOAuthFlow),
+		ClientID:      readFlagOrConfig(ctx, FlagOAuthClientID),
+		ClientSecret:  clientSecret,
+		TokenURL:      readFlagOrConfig(ctx, FlagOAuthTokenURL),
+		DeviceAuthURL: readFlagOrConfig(ctx, FlagOAuthDeviceAuthURL),
+		Scopes: strings.FieldsFunc(readFlagOrConfig(ctx, FlagOAuthScopes), func(r rune) bool {
+			return r == ' ' || r == ','
+		}),
+		Audience: readFlagOrConfig(ctx, FlagOAuthAudience),
+		CacheDir: cacheDir,
+		// completion output is discarded by the shell, so a login prompt would hang it
+		NonInteractive: isCompleting(),
+	})
+}
+
 func stopPlugins(ctx *cli.Context) error {
 	plugin.StopPlugins()
 
@@ -211,6 +329,15 @@ func handleError(c *cli.Context, err error) {
 		return
 	}
 
+	// exit codes that reflect an outcome rather than a failure of tctl, such as the status of a workflow
+	if exitErr, ok := err.(cli.ExitCoder); ok {
+		if msg := exitErr.Error(); msg != "" {
+			fmt.Fprintln(os.Stderr, msg)
+		}
+		cli.OsExiter(exitErr.ExitCode())
+		return
+	}
+
 	fmt.Fprintf(os.Stderr, "%s %+v\n", color.Red(c, "Error:"), err)
 	if os.Getenv(showErrorStackEnv) != `` {
 		fmt.Fprintln(os.Stderr, color.Magenta(c, "Stack trace:"))
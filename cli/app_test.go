@@ -26,6 +26,7 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -38,6 +39,7 @@ import (
 	enumspb "go.temporal.io/api/enums/v1"
 	historypb "go.temporal.io/api/history/v1"
 	namespacepb "go.temporal.io/api/namespace/v1"
+	querypb "go.temporal.io/api/query/v1"
 	replicationpb "go.temporal.io/api/replication/v1"
 	"go.temporal.io/api/serviceerror"
 	taskqueuepb "go.temporal.io/api/taskqueue/v1"
@@ -65,15 +67,15 @@ type clientFactoryMock struct {
 	sdkClient      *sdkmocks.Client
 }
 
-func (m *clientFactoryMock) FrontendClient(c *cli.Context) workflowservice.WorkflowServiceClient {
-	return m.frontendClient
+func (m *clientFactoryMock) FrontendClient(c *cli.Context) (workflowservice.WorkflowServiceClient, error) {
+	return m.frontendClient, nil
 }
 
 func (m *clientFactoryMock) SDKClient(c *cli.Context, namespace string) sdkclient.Client {
 	return m.sdkClient
 }
 
-func (m *clientFactoryMock) HealthClient(_ *cli.Context) healthpb.HealthClient {
+func (m *clientFactoryMock) HealthClient(_ *cli.Context) (healthpb.HealthClient, error) {
 	panic("HealthClient mock is not supported")
 }
 
@@ -339,6 +341,73 @@ func (s *cliAppSuite) TestQueryWorkflow_Failed() {
 	s.Equal(1, errorCode)
 }
 
+func (s *cliAppSuite) TestQueryWorkflow_JSONOutput() {
+	result, err := payloads.Encode("query-result", map[string]int{"count": 1})
+	s.NoError(err)
+	s.frontendClient.EXPECT().QueryWorkflow(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *workflowservice.QueryWorkflowRequest, _ ...interface{}) (*workflowservice.QueryWorkflowResponse, error) {
+			s.Equal(enumspb.QUERY_REJECT_CONDITION_NOT_OPEN, req.GetQueryRejectCondition())
+			return &workflowservice.QueryWorkflowResponse{QueryResult: result}, nil
+		})
+
+	out := captureStdout(s.T(), func() {
+		err = s.app.Run([]string{"", "--namespace", cliTestNamespace, "workflow", "query", "--workflow-id", "wid", "--query-type", "query-type-test",
+			"--reject-condition", "not_open", "--output", "json"})
+		s.Nil(err)
+	})
+
+	var res struct {
+		QueryType      string        `json:"queryType"`
+		Result         []interface{} `json:"result"`
+		RejectedStatus string        `json:"rejectedStatus"`
+	}
+	s.NoError(json.Unmarshal([]byte(out), &res), out)
+	s.Equal("query-type-test", res.QueryType)
+	s.Equal([]interface{}{"query-result", map[string]interface{}{"count": float64(1)}}, res.Result)
+	s.Empty(res.RejectedStatus)
+}
+
+func (s *cliAppSuite) TestQueryWorkflow_Rejected() {
+	s.frontendClient.EXPECT().QueryWorkflow(gomock.Any(), gomock.Any()).Return(&workflowservice.QueryWorkflowResponse{
+		QueryRejected: &querypb.QueryRejected{Status: enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED},
+	}, nil).Times(2)
+
+	out := captureStdout(s.T(), func() {
+		err := s.app.Run([]string{"", "--namespace", cliTestNamespace, "workflow", "query", "--workflow-id", "wid", "--query-type", "query-type-test",
+			"--reject-condition", "not_open", "--output", "json"})
+		s.Nil(err)
+	})
+	var res map[string]interface{}
+	s.NoError(json.Unmarshal([]byte(out), &res), out)
+	s.Equal(map[string]interface{}{"queryType": "query-type-test", "rejectedStatus": "Completed"}, res)
+
+	out = captureStdout(s.T(), func() {
+		err := s.app.Run([]string{"", "--namespace", cliTestNamespace, "workflow", "query", "--workflow-id", "wid", "--query-type", "query-type-test"})
+		s.Nil(err)
+	})
+	s.Equal("Query was rejected, workflow has status: Completed\n", out)
+}
+
+func (s *cliAppSuite) TestQueryWorkflow_ListQueryTypes() {
+	metadata := &commonpb.Payloads{Payloads: []*commonpb.Payload{{
+		Metadata: map[string][]byte{"encoding": []byte("json/protobuf")},
+		Data:     []byte(`{"definition": {"type": "wf", "queryDefinitions": [{"name": "state", "description": "current state"}]}}`),
+	}}}
+	s.frontendClient.EXPECT().QueryWorkflow(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *workflowservice.QueryWorkflowRequest, _ ...interface{}) (*workflowservice.QueryWorkflowResponse, error) {
+			s.Equal(workflowMetadataQueryType, req.GetQuery().GetQueryType())
+			return &workflowservice.QueryWorkflowResponse{QueryResult: metadata}, nil
+		})
+
+	out := captureStdout(s.T(), func() {
+		err := s.app.Run([]string{"", "--namespace", cliTestNamespace, "workflow", "query", "--workflow-id", "wid", "--output", "json"})
+		s.Nil(err)
+	})
+	var types []map[string]interface{}
+	s.NoError(json.Unmarshal([]byte(out), &types), out)
+	s.Equal([]map[string]interface{}{{"Name": "state", "Description": "current state"}}, types)
+}
+
 var (
 	status = enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED
 
@@ -486,6 +555,58 @@ func (s *cliAppSuite) TestDescribeTaskQueue_Activity() {
 	s.sdkClient.AssertExpectations(s.T())
 }
 
+func (s *cliAppSuite) TestTaskQueueStatus() {
+	lastPoll := time.Now().UTC().Truncate(time.Second)
+	stalePoll := lastPoll.Add(-time.Hour)
+	s.frontendClient.EXPECT().ListTaskQueuePartitions(gomock.Any(), gomock.Any()).Return(&workflowservice.ListTaskQueuePartitionsResponse{
+		WorkflowTaskQueuePartitions: []*taskqueuepb.TaskQueuePartitionMetadata{{Key: "test-taskQueue", OwnerHostName: "host-1"}},
+		ActivityTaskQueuePartitions: []*taskqueuepb.TaskQueuePartitionMetadata{{Key: "test-taskQueue", OwnerHostName: "host-2"}},
+	}, nil)
+	s.frontendClient.EXPECT().DescribeTaskQueue(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *workflowservice.DescribeTaskQueueRequest, _ ...interface{}) (*workflowservice.DescribeTaskQueueResponse, error) {
+			s.True(req.GetIncludeTaskQueueStatus())
+			resp := &workflowservice.DescribeTaskQueueResponse{
+				Pollers: []*taskqueuepb.PollerInfo{{Identity: "worker-1", LastAccessTime: &lastPoll}},
+			}
+			if req.GetTaskQueueType() == enumspb.TASK_QUEUE_TYPE_ACTIVITY {
+				resp.Pollers = append(resp.Pollers, &taskqueuepb.PollerInfo{Identity: "worker-2", LastAccessTime: &stalePoll})
+				resp.TaskQueueStatus = &taskqueuepb.TaskQueueStatus{BacklogCountHint: 10}
+			}
+			return resp, nil
+		}).Times(2)
+
+	out := captureStdout(s.T(), func() {
+		err := s.app.Run([]string{"", "--namespace", cliTestNamespace, "task-queue", "status", "--task-queue", "test-taskQueue", "--output", "json"})
+		s.Nil(err)
+	})
+
+	var status struct {
+		Status     []taskQueueTypeStatus
+		Pollers    []taskQueuePoller
+		Partitions []taskQueuePartition
+	}
+	s.NoError(json.Unmarshal([]byte(out), &status), out)
+	s.Equal([]taskQueueTypeStatus{
+		{Type: "Workflow", Pollers: 1, BacklogCountHint: "unknown", Partitions: 1},
+		{Type: "Activity", Pollers: 2, StalePollers: 1, BacklogCountHint: "10", Partitions: 1},
+	}, status.Status)
+	s.Equal([]taskQueuePoller{
+		{Identity: "worker-1", LastWorkflowTaskPoll: &lastPoll, LastActivityTaskPoll: &lastPoll},
+		{Identity: "worker-2", LastActivityTaskPoll: &stalePoll, Stale: true},
+	}, status.Pollers)
+	s.Equal([]taskQueuePartition{
+		{Type: "Workflow", Key: "test-taskQueue", OwnerHostName: "host-1"},
+		{Type: "Activity", Key: "test-taskQueue", OwnerHostName: "host-2"},
+	}, status.Partitions)
+}
+
+func (s *cliAppSuite) TestIsStalePoller() {
+	now := time.Now()
+	s.False(isStalePoller(&taskqueuepb.PollerInfo{LastAccessTime: timestamp.TimePtr(now.Add(-time.Minute))}, now, 2*time.Minute))
+	s.True(isStalePoller(&taskqueuepb.PollerInfo{LastAccessTime: timestamp.TimePtr(now.Add(-3 * time.Minute))}, now, 2*time.Minute))
+	s.True(isStalePoller(&taskqueuepb.PollerInfo{}, now, 2*time.Minute))
+}
+
 // TestParseTime tests the parsing of date argument in UTC and UnixNano formats
 func (s *cliAppSuite) TestParseTime() {
 	t, err := parseTime("", time.Date(1978, 8, 22, 0, 0, 0, 0, time.UTC), time.Now().UTC())
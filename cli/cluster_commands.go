@@ -40,7 +40,10 @@ const (
 
 // HealthCheck check frontend health.
 func HealthCheck(c *cli.Context) error {
-	healthClient := cFactory.HealthClient(c)
+	healthClient, err := cFactory.HealthClient(c)
+	if err != nil {
+		return err
+	}
 	ctx, cancel := newContext(c)
 	defer cancel()
 
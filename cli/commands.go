@@ -51,5 +51,17 @@ var tctlCommands = []*cli.Command{
 		Usage:       "Configure tctl",
 		Subcommands: newConfigCommands(),
 	},
+	{
+		Name:        "plugin",
+		Usage:       "Operations on tctl plugins",
+		Description: pluginDescription,
+		Subcommands: newPluginCommands(),
+	},
+	{
+		Name:        "completion",
+		Usage:       "Output shell completion code for bash, zsh or fish",
+		Description: completionDescription,
+		Subcommands: newCompletionCommands(),
+	},
 	newAliasCommand(),
 }
@@ -0,0 +1,439 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	enumspb "go.temporal.io/api/enums/v1"
+	filterpb "go.temporal.io/api/filter/v1"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+
+	"github.com/temporalio/tctl-kit/pkg/color"
+	"github.com/temporalio/tctl/cli/headersprovider"
+	"github.com/temporalio/tctl/config"
+)
+
+const (
+	completionFlag = "--generate-bash-completion"
+	// completion values fetched from the server are reused for this long
+	completionCacheTTL = time.Minute
+	// completion must not keep the shell waiting on an unreachable server
+	completionTimeout  = 2 * time.Second
+	completionPageSize = 100
+)
+
+const completionDescription = `Output a completion script for the given shell. To load completions:
+
+   bash:  source <(tctl completion bash)
+   zsh:   source <(tctl completion zsh)
+   fish:  tctl completion fish | source
+
+Besides commands and flags, namespaces, workflow Ids, task queues, search attributes
+and enum values are completed. Values fetched from the server are cached for a minute.`
+
+const bashCompletionTemplate = `# bash completion for {{.}}
+
+_{{.}}_completions() {
+  local cur opts
+  COMPREPLY=()
+  cur="${COMP_WORDS[COMP_CWORD]}"
+  if [[ "$cur" == -* ]]; then
+    opts=$("${COMP_WORDS[@]:0:$COMP_CWORD}" "$cur" ` + completionFlag + ` 2>/dev/null)
+  else
+    opts=$("${COMP_WORDS[@]:0:$COMP_CWORD}" ` + completionFlag + ` 2>/dev/null)
+  fi
+  local IFS=$'\n'
+  COMPREPLY=($(compgen -W "$opts" -- "$cur"))
+}
+
+complete -o bashdefault -o default -F _{{.}}_completions {{.}}
+`
+
+const zshCompletionTemplate = `#compdef {{.}}
+
+_{{.}}() {
+  local -a opts
+  local cur
+  cur=${words[-1]}
+  if [[ "$cur" == -* ]]; then
+    opts=("${(@f)$(_CLI_ZSH_AUTOCOMPLETE_HACK=1 ${words[@]:0:#words[@]-1} ${cur} ` + completionFlag + ` 2>/dev/null)}")
+  else
+    opts=("${(@f)$(_CLI_ZSH_AUTOCOMPLETE_HACK=1 ${words[@]:0:#words[@]-1} ` + completionFlag + ` 2>/dev/null)}")
+  fi
+
+  if [[ "${opts[1]}" != "" ]]; then
+    _describe 'values' opts
+  else
+    _files
+  fi
+}
+
+compdef _{{.}} {{.}}
+`
+
+const fishCompletionTemplate = `# fish completion for {{.}}
+
+function __{{.}}_complete
+    set -l args (commandline -opc)
+    set -l cur (commandline -ct)
+    if string match -q -- '-*' "$cur"
+        set -a args $cur
+    end
+    $args ` + completionFlag + ` 2>/dev/null
+end
+
+complete -c {{.}} -f -a '(__{{.}}_complete)'
+`
+
+// flagValueCompleters suggest values for flags, keyed by the flag name
+var flagValueCompleters = map[string]func(c *cli.Context) ([]string, error){
+	FlagNamespace:          completeNamespaces,
+	FlagWorkflowID:         completeWorkflowIDs,
+	FlagTaskQueue:          completeTaskQueues,
+	FlagSearchAttributeKey: completeSearchAttributes,
+	FlagResetType:          completeValues(mapKeysToArray(resetTypesMap)...),
+	FlagResetReapplyType:   completeValues("Signal", "None"),
+	FlagWorkflowIDReusePolicy: func(c *cli.Context) ([]string, error) {
+		var values []string
+		for name, value := range enumspb.WorkflowIdReusePolicy_value {
+			if value != int32(enumspb.WORKFLOW_ID_REUSE_POLICY_UNSPECIFIED) {
+				values = append(values, name)
+			}
+		}
+		return values, nil
+	},
+	FlagOAuthFlow:   completeValues(headersprovider.OAuthFlowClientCredentials, headersprovider.OAuthFlowDeviceCode),
+	color.FlagColor: completeValues(string(color.Auto), string(color.Always), string(color.Never)),
+}
+
+func newCompletionCommands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "bash",
+			Usage: "Output the bash completion script",
+			Action: func(c *cli.Context) error {
+				return writeCompletionScript(c, bashCompletionTemplate)
+			},
+		},
+		{
+			Name:  "zsh",
+			Usage: "Output the zsh completion script",
+			Action: func(c *cli.Context) error {
+				return writeCompletionScript(c, zshCompletionTemplate)
+			},
+		},
+		{
+			Name:  "fish",
+			Usage: "Output the fish completion script",
+			Action: func(c *cli.Context) error {
+				return writeCompletionScript(c, fishCompletionTemplate)
+			},
+		},
+	}
+}
+
+func writeCompletionScript(c *cli.Context, script string) error {
+	tmpl, err := template.New("completion").Parse(script)
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(c.App.Writer, rootApp(c).Name)
+}
+
+// useCompletion completes flag values of the app and of all commands without subcommands
+func useCompletion(app *cli.App) {
+	fallback := app.BashComplete
+	if fallback == nil {
+		fallback = cli.DefaultAppComplete
+	}
+	app.BashComplete = completeFlagValues(app.Flags, fallback)
+
+	var walk func(commands []*cli.Command)
+	walk = func(commands []*cli.Command) {
+		for _, cmd := range commands {
+			if len(cmd.Subcommands) > 0 {
+				walk(cmd.Subcommands)
+				continue
+			}
+			if cmd.BashComplete == nil {
+				cmd.BashComplete = completeFlagValues(cmd.Flags, cli.DefaultCompleteWithFlags(cmd))
+			}
+		}
+	}
+	walk(app.Commands)
+}
+
+// completeFlagValues suggests values if the word being completed is the value of a flag,
+// otherwise it falls back to the given completion
+func completeFlagValues(flags []cli.Flag, fallback cli.BashCompleteFunc) cli.BashCompleteFunc {
+	return func(c *cli.Context) {
+		complete := flagValueCompleter(flags, completedArgs(os.Args))
+		if complete == nil {
+			fallback(c)
+			return
+		}
+
+		// suggestions are best effort, errors would only clutter the prompt
+		values, err := complete(c)
+		if err != nil {
+			return
+		}
+		printCompletions(c.App.Writer, values)
+	}
+}
+
+// isCompleting returns whether tctl was invoked by a shell to complete the command line
+func isCompleting() bool {
+	return len(os.Args) > 0 && os.Args[len(os.Args)-1] == completionFlag
+}
+
+// completedArgs returns the arguments preceding the word being completed
+func completedArgs(args []string) []string {
+	if len(args) == 0 || args[len(args)-1] != completionFlag {
+		return nil
+	}
+
+	return args[1 : len(args)-1]
+}
+
+// flagValueCompleter returns the completer for the flag the last argument refers to, if any
+func flagValueCompleter(flags []cli.Flag, args []string) func(c *cli.Context) ([]string, error) {
+	if len(args) == 0 {
+		return nil
+	}
+
+	last := args[len(args)-1]
+	if !strings.HasPrefix(last, "-") || strings.Contains(last, "=") {
+		return nil
+	}
+	name := strings.TrimLeft(last, "-")
+
+	for _, flag := range flags {
+		for _, n := range flag.Names() {
+			if n == name {
+				return flagValueCompleters[flag.Names()[0]]
+			}
+		}
+	}
+
+	return nil
+}
+
+func printCompletions(w io.Writer, values []string) {
+	sort.Strings(values)
+	for _, value := range values {
+		if os.Getenv("_CLI_ZSH_AUTOCOMPLETE_HACK") == "1" {
+			// zsh separates values from their descriptions with a colon
+			value = strings.ReplaceAll(value, ":", `\:`)
+		}
+		fmt.Fprintln(w, value)
+	}
+}
+
+func completeValues(values ...string) func(c *cli.Context) ([]string, error) {
+	return func(c *cli.Context) ([]string, error) {
+		return values, nil
+	}
+}
+
+func completeNamespaces(c *cli.Context) ([]string, error) {
+	return cachedCompletions(c, "namespaces", func(ctx context.Context, client workflowservice.WorkflowServiceClient) ([]string, error) {
+		resp, err := client.ListNamespaces(ctx, &workflowservice.ListNamespacesRequest{PageSize: completionPageSize})
+		if err != nil {
+			return nil, err
+		}
+
+		var values []string
+		for _, ns := range resp.GetNamespaces() {
+			values = append(values, ns.GetNamespaceInfo().GetName())
+		}
+		return values, nil
+	})
+}
+
+func completeWorkflowIDs(c *cli.Context) ([]string, error) {
+	return cachedCompletions(c, "workflow-ids", func(ctx context.Context, client workflowservice.WorkflowServiceClient) ([]string, error) {
+		executions, err := listRecentOpenExecutions(ctx, c, client)
+		if err != nil {
+			return nil, err
+		}
+
+		var values []string
+		for _, execution := range executions {
+			values = append(values, execution.GetExecution().GetWorkflowId())
+		}
+		return uniqueValues(values), nil
+	})
+}
+
+func completeTaskQueues(c *cli.Context) ([]string, error) {
+	// there is no API to list task queues, so they are taken from running workflows
+	return cachedCompletions(c, "task-queues", func(ctx context.Context, client workflowservice.WorkflowServiceClient) ([]string, error) {
+		executions, err := listRecentOpenExecutions(ctx, c, client)
+		if err != nil {
+			return nil, err
+		}
+
+		var values []string
+		for _, execution := range executions {
+			if tq := execution.GetTaskQueue(); tq != "" {
+				values = append(values, tq)
+			}
+		}
+		return uniqueValues(values), nil
+	})
+}
+
+func completeSearchAttributes(c *cli.Context) ([]string, error) {
+	return cachedCompletions(c, "search-attributes", func(ctx context.Context, client workflowservice.WorkflowServiceClient) ([]string, error) {
+		resp, err := client.GetSearchAttributes(ctx, &workflowservice.GetSearchAttributesRequest{})
+		if err != nil {
+			return nil, err
+		}
+
+		var values []string
+		for name := range resp.GetKeys() {
+			values = append(values, name)
+		}
+		return values, nil
+	})
+}
+
+func listRecentOpenExecutions(ctx context.Context, c *cli.Context, client workflowservice.WorkflowServiceClient) ([]*workflowpb.WorkflowExecutionInfo, error) {
+	latest := time.Now()
+	resp, err := client.ListOpenWorkflowExecutions(ctx, &workflowservice.ListOpenWorkflowExecutionsRequest{
+		Namespace:       readFlagOrConfig(c, FlagNamespace),
+		MaximumPageSize: completionPageSize,
+		StartTimeFilter: &filterpb.StartTimeFilter{
+			EarliestTime: &time.Time{},
+			LatestTime:   &latest,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.GetExecutions(), nil
+}
+
+func uniqueValues(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var unique []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
+// cachedCompletions returns the values cached for the server and namespace in use,
+// fetching them if the cache is missing or has expired
+func cachedCompletions(c *cli.Context, kind string, fetch func(ctx context.Context, client workflowservice.WorkflowServiceClient) ([]string, error)) ([]string, error) {
+	cachePath, err := completionCachePath(kind, readFlagOrConfig(c, FlagAddress), readFlagOrConfig(c, FlagNamespace))
+	if err != nil {
+		return nil, err
+	}
+
+	if values, ok := readCompletionCache(cachePath, time.Now()); ok {
+		return values, nil
+	}
+
+	ctx, cancel := NewContextWithTimeoutAndCLIHeaders(completionTimeout)
+	defer cancel()
+
+	client, err := cFactory.FrontendClient(c)
+	if err != nil {
+		return nil, err
+	}
+	values, err := fetch(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	writeCompletionCache(cachePath, values)
+
+	return values, nil
+}
+
+func completionCachePath(kind, address, namespace string) (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	for _, part := range []string{address, namespace} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+
+	return filepath.Join(dir, "completion", kind+"-"+hex.EncodeToString(h.Sum(nil))[:16]+".json"), nil
+}
+
+func readCompletionCache(path string, now time.Time) ([]string, bool) {
+	info, err := os.Stat(path)
+	if err != nil || now.Sub(info.ModTime()) > completionCacheTTL {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var values []string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, false
+	}
+
+	return values, true
+}
+
+func writeCompletionCache(path string, values []string) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return
+	}
+
+	// failing to cache only means that the values are fetched again next time
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0600)
+}
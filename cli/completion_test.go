@@ -0,0 +1,98 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	namespacepb "go.temporal.io/api/namespace/v1"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+func TestFlagValueCompleter(t *testing.T) {
+	flags := []cli.Flag{
+		&cli.StringFlag{Name: FlagWorkflowID, Aliases: FlagWorkflowIDAlias},
+		&cli.StringFlag{Name: FlagRunID, Aliases: FlagRunIDAlias},
+	}
+
+	require.NotNil(t, flagValueCompleter(flags, []string{"workflow", "describe", "--workflow-id"}))
+	require.NotNil(t, flagValueCompleter(flags, []string{"workflow", "describe", "-wid"}))
+	// flags without completion, flags of other commands and arguments
+	require.Nil(t, flagValueCompleter(flags, []string{"workflow", "describe", "--run-id"}))
+	require.Nil(t, flagValueCompleter(flags, []string{"workflow", "describe", "--task-queue"}))
+	require.Nil(t, flagValueCompleter(flags, []string{"workflow", "describe", "--workflow-id=wid"}))
+	require.Nil(t, flagValueCompleter(flags, []string{"workflow", "describe"}))
+	require.Nil(t, flagValueCompleter(flags, nil))
+}
+
+func TestCompletedArgs(t *testing.T) {
+	require.Equal(t, []string{"workflow", "describe", "--wid"}, completedArgs([]string{"tctl", "workflow", "describe", "--wid", completionFlag}))
+	require.Nil(t, completedArgs([]string{"tctl", "workflow", "describe", "--wid"}))
+}
+
+func TestCompletionCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "completion", "namespaces.json")
+
+	_, ok := readCompletionCache(path, time.Now())
+	require.False(t, ok)
+
+	writeCompletionCache(path, []string{"default", "orders"})
+	values, ok := readCompletionCache(path, time.Now())
+	require.True(t, ok)
+	require.Equal(t, []string{"default", "orders"}, values)
+
+	_, ok = readCompletionCache(path, time.Now().Add(completionCacheTTL+time.Second))
+	require.False(t, ok)
+}
+
+func (s *cliAppSuite) TestCompleteNamespaces() {
+	s.T().Setenv("HOME", s.T().TempDir())
+	s.frontendClient.EXPECT().ListNamespaces(gomock.Any(), gomock.Any()).Return(&workflowservice.ListNamespacesResponse{
+		Namespaces: []*workflowservice.DescribeNamespaceResponse{
+			{NamespaceInfo: &namespacepb.NamespaceInfo{Name: "orders"}},
+			{NamespaceInfo: &namespacepb.NamespaceInfo{Name: "default"}},
+		},
+	}, nil).Times(1)
+
+	args := []string{"tctl", "--namespace", completionFlag}
+	defer func(osArgs []string) { os.Args = osArgs }(os.Args)
+	os.Args = args
+
+	var out bytes.Buffer
+	s.app.Writer = &out
+	defer func() { s.app.Writer = os.Stdout }()
+
+	// the second completion is served from the cache
+	for i := 0; i < 2; i++ {
+		out.Reset()
+		s.NoError(s.app.Run(args))
+		s.Equal("default\norders\n", out.String())
+	}
+}
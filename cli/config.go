@@ -23,15 +23,25 @@
 package cli
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
 
 	"github.com/temporalio/tctl-kit/pkg/color"
 	"github.com/temporalio/tctl-kit/pkg/config"
+	"github.com/temporalio/tctl-kit/pkg/flags"
+	"github.com/temporalio/tctl-kit/pkg/output"
 )
 
+const defaultEnvironment = "local"
+
 var (
 	rootKeys = []string{
 		config.KeyActive,
@@ -40,13 +50,31 @@ var (
 	envKeys = []string{
 		FlagNamespace,
 		FlagAddress,
+		FlagAuth,
+		FlagContextTimeout,
+		FlagAutoConfirm,
 		FlagTLSCertPath,
 		FlagTLSKeyPath,
 		FlagTLSCaPath,
 		FlagTLSDisableHostVerification,
 		FlagTLSServerName,
+		FlagHeadersProviderPlugin,
+		FlagDataConverterPlugin,
+		FlagCodecEndpoint,
+		FlagCodecAuth,
+		FlagGRPCMeta,
+		FlagOAuthFlow,
+		FlagOAuthClientID,
+		FlagOAuthClientSecret,
+		FlagOAuthTokenURL,
+		FlagOAuthDeviceAuthURL,
+		FlagOAuthScopes,
+		FlagOAuthAudience,
+		color.FlagColor,
 	}
 	keys = append(rootKeys, envKeys...)
+
+	envNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
 )
 
 func newConfigCommands() []*cli.Command {
@@ -54,7 +82,7 @@ func newConfigCommands() []*cli.Command {
 		{
 			Name:  "get",
 			Usage: "Print config values",
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				&cli.BoolFlag{
 					Name:  config.KeyActive,
 					Usage: "Print active environment",
@@ -63,39 +91,7 @@ func newConfigCommands() []*cli.Command {
 					Name:  config.KeyAlias,
 					Usage: "Print command aliases",
 				},
-				&cli.BoolFlag{
-					Name:  FlagNamespace,
-					Usage: "Print default namespace (for active environment)",
-				},
-				&cli.BoolFlag{
-					Name:  FlagAddress,
-					Usage: "Print Temporal address (for active environment)",
-				},
-				&cli.StringFlag{
-					Name:  FlagTLSCertPath,
-					Value: "",
-					Usage: "Print path to x509 certificate",
-				},
-				&cli.StringFlag{
-					Name:  FlagTLSKeyPath,
-					Value: "",
-					Usage: "Print path to private key",
-				},
-				&cli.StringFlag{
-					Name:  FlagTLSCaPath,
-					Value: "",
-					Usage: "Print path to server CA certificate",
-				},
-				&cli.BoolFlag{
-					Name:  FlagTLSDisableHostVerification,
-					Usage: "Print whether tls host name verification is disabled",
-				},
-				&cli.StringFlag{
-					Name:  FlagTLSServerName,
-					Value: "",
-					Usage: "Print target TLS server name",
-				},
-			},
+			}, newEnvFlagsForGet()...),
 			Action: func(c *cli.Context) error {
 				return GetValue(c)
 			},
@@ -103,11 +99,11 @@ func newConfigCommands() []*cli.Command {
 		{
 			Name:  "set",
 			Usage: "Set config values",
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				&cli.StringFlag{
 					Name:  config.KeyActive,
 					Usage: "Activate environment",
-					Value: "local",
+					Value: defaultEnvironment,
 				},
 				&cli.StringFlag{
 					Name:  config.KeyAlias,
@@ -117,48 +113,154 @@ func newConfigCommands() []*cli.Command {
 					Name:  "version",
 					Usage: "Opt-in to a new TCTL UX, values: (current, next)",
 				},
+			}, newEnvFlags()...),
+			Action: func(c *cli.Context) error {
+				return SetValue(c)
+			},
+		},
+		{
+			Name:        "env",
+			Usage:       "Manage environments",
+			Subcommands: newConfigEnvCommands(),
+		},
+		{
+			Name:  "export",
+			Usage: "Print an environment as YAML that can be imported with 'config import'",
+			Flags: []cli.Flag{
 				&cli.StringFlag{
-					Name:  FlagNamespace,
-					Usage: "Default namespace",
-				},
-				&cli.StringFlag{
-					Name:  FlagAddress,
-					Usage: "host:port for Temporal frontend service",
-					Value: localHostPort,
-				},
-				&cli.StringFlag{
-					Name:  FlagTLSCertPath,
-					Value: "",
-					Usage: "Path to x509 certificate",
+					Name:  FlagEnv,
+					Usage: "Environment to export (active environment by default)",
 				},
-				&cli.StringFlag{
-					Name:  FlagTLSKeyPath,
-					Value: "",
-					Usage: "Path to private key",
+				&cli.BoolFlag{
+					Name:  FlagIncludeSecrets,
+					Usage: "Include plaintext secrets. Secret references are always included",
 				},
-				&cli.StringFlag{
-					Name:  FlagTLSCaPath,
-					Value: "",
-					Usage: "Path to server CA certificate",
+			},
+			Action: func(c *cli.Context) error {
+				return ExportConfig(c)
+			},
+		},
+		{
+			Name:      "import",
+			Usage:     "Import environments from a YAML file created with 'config export'",
+			ArgsUsage: "[options] <file>",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  FlagOverwrite,
+					Usage: "Replace existing environments with the same name",
 				},
 				&cli.BoolFlag{
-					Name:  FlagTLSDisableHostVerification,
-					Usage: "Disable TLS host name verification (TLS must be enabled)",
+					Name:  FlagYes,
+					Usage: "Import env:, file: and exec: references without a confirmation prompt",
 				},
+			},
+			Action: func(c *cli.Context) error {
+				return ImportConfig(c)
+			},
+		},
+		{
+			Name:  "validate",
+			Usage: "Check that an environment and the aliases are usable",
+			Flags: []cli.Flag{
 				&cli.StringFlag{
-					Name:  FlagTLSServerName,
-					Value: "",
-					Usage: "Override for target server name",
+					Name:  FlagEnv,
+					Usage: "Environment to validate (active environment by default)",
 				},
 			},
 			Action: func(c *cli.Context) error {
-				return SetValue(c)
+				return ValidateConfig(c)
+			},
+		},
+	}
+}
+
+func newConfigEnvCommands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "list",
+			Usage: "List environments",
+			Flags: flags.FlagsForRendering,
+			Action: func(c *cli.Context) error {
+				return ListEnvironments(c)
+			},
+		},
+		{
+			Name:      "show",
+			Usage:     "Show properties of an environment (active environment by default)",
+			ArgsUsage: "[name]",
+			Action: func(c *cli.Context) error {
+				return ShowEnvironment(c)
+			},
+		},
+		{
+			Name:      "create",
+			Usage:     "Create an environment",
+			ArgsUsage: "[options] <name>",
+			Flags:     newEnvFlags(),
+			Action: func(c *cli.Context) error {
+				return CreateEnvironment(c)
+			},
+		},
+		{
+			Name:      "delete",
+			Usage:     "Delete an environment",
+			ArgsUsage: "<name>",
+			Action: func(c *cli.Context) error {
+				return DeleteEnvironment(c)
+			},
+		},
+		{
+			Name:      "use",
+			Usage:     "Activate an environment",
+			ArgsUsage: "<name>",
+			Action: func(c *cli.Context) error {
+				return UseEnvironment(c)
 			},
 		},
 	}
 }
 
+// newEnvFlags returns a flag for each global flag that can be stored in an environment
+func newEnvFlags() []cli.Flag {
+	var envFlags []cli.Flag
+	for _, f := range newGlobalFlags() {
+		name := f.Names()[0]
+		if !isEnvKey(name) {
+			continue
+		}
+
+		var usage string
+		if df, ok := f.(cli.DocGenerationFlag); ok {
+			usage = df.GetUsage()
+		}
+
+		switch f.(type) {
+		case *cli.BoolFlag:
+			envFlags = append(envFlags, &cli.BoolFlag{Name: name, Usage: usage})
+		case *cli.StringSliceFlag:
+			envFlags = append(envFlags, &cli.StringSliceFlag{Name: name, Usage: usage})
+		default:
+			envFlags = append(envFlags, &cli.StringFlag{Name: name, Usage: usage})
+		}
+	}
+
+	return envFlags
+}
+
+func newEnvFlagsForGet() []cli.Flag {
+	var envFlags []cli.Flag
+	for _, key := range envKeys {
+		envFlags = append(envFlags, &cli.BoolFlag{
+			Name:  key,
+			Usage: fmt.Sprintf("Print %s (for active environment)", key),
+		})
+	}
+
+	return envFlags
+}
+
 func GetValue(c *cli.Context) error {
+	env := selectedEnvironment(c)
 	for _, key := range keys {
 		if !c.IsSet(key) {
 			continue
@@ -169,7 +271,7 @@ func GetValue(c *cli.Context) error {
 		if isRootKey(key) {
 			val, err = tctlConfig.Get(c, key)
 		} else if isEnvKey(key) {
-			val, err = tctlConfig.GetByEnvironment(c, key)
+			val, err = tctlConfig.Get(c, envConfigKey(env, key))
 		} else {
 			return fmt.Errorf("invalid key: %s", key)
 		}
@@ -177,56 +279,460 @@ func GetValue(c *cli.Context) error {
 			return err
 		}
 
-		fmt.Printf("%v: %v\n", color.Magenta(c, "%v", key), val)
+		fmt.Printf("%v: %v\n", color.Magenta(c, "%v", key), redactConfigValue(key, fmt.Sprintf("%v", val)))
 	}
 	return nil
 }
 
 func SetValue(c *cli.Context) error {
+	env := selectedEnvironment(c)
 	for _, key := range keys {
 		if !c.IsSet(key) {
 			continue
 		}
 
-		val := c.String(key)
+		val, err := envFlagValue(c, key)
+		if err != nil {
+			return err
+		}
+
 		if isRootKey(key) {
 			if err := tctlConfig.Set(c, key, val); err != nil {
 				return fmt.Errorf("unable to set property %s: %s", key, err)
 			}
 		} else if isEnvKey(key) {
-			if err := tctlConfig.SetByEnvironment(c, key, val); err != nil {
+			if err := tctlConfig.Set(c, envConfigKey(env, key), val); err != nil {
 				return fmt.Errorf("unable to set property %s: %s", key, err)
 			}
 		} else {
 			return fmt.Errorf("unable to set property %s: invalid key", key)
 		}
-		fmt.Printf("%v: %v\n", color.Magenta(c, "%v", key), val)
+		fmt.Printf("%v: %v\n", color.Magenta(c, "%v", key), redactConfigValue(key, val))
+	}
+
+	return nil
+}
+
+// ListEnvironments prints the environments stored in the config
+func ListEnvironments(c *cli.Context) error {
+	envs, err := tctlConfig.Environments()
+	if err != nil {
+		return fmt.Errorf("unable to list environments: %s", err)
+	}
+
+	active, _ := tctlConfig.Get(c, config.KeyActive)
+	type envItem struct {
+		Name      string
+		Active    bool
+		Address   string
+		Namespace string
+	}
+	var items []interface{}
+	for _, env := range envs {
+		address, _ := tctlConfig.Get(c, envConfigKey(env, FlagAddress))
+		namespace, _ := tctlConfig.Get(c, envConfigKey(env, FlagNamespace))
+		items = append(items, envItem{Name: env, Active: env == active, Address: address, Namespace: namespace})
+	}
+
+	opts := &output.PrintOptions{
+		Fields:  []string{"Name", "Active", "Address", "Namespace"},
+		NoPager: true,
+	}
+	output.PrintItems(c, items, opts)
+
+	return nil
+}
+
+// ShowEnvironment prints the properties of an environment
+func ShowEnvironment(c *cli.Context) error {
+	env := c.Args().First()
+	if env == "" {
+		env = selectedEnvironment(c)
+	}
+
+	props, err := tctlConfig.Environment(env)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(props))
+	for key := range props {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+
+	fmt.Printf("%v: %v\n", color.Magenta(c, "%v", "name"), env)
+	for _, key := range names {
+		fmt.Printf("%v: %v\n", color.Magenta(c, "%v", key), redactConfigValue(key, props[key]))
+	}
+
+	return nil
+}
+
+// CreateEnvironment creates an environment with the properties passed as flags
+func CreateEnvironment(c *cli.Context) error {
+	env := c.Args().First()
+	if c.Args().Len() > 1 {
+		return fmt.Errorf("unexpected arguments %v, options must be passed before the environment name", c.Args().Tail())
+	}
+	if !envNameRegexp.MatchString(env) {
+		return fmt.Errorf("invalid environment name %q: must consist of lowercase letters, digits, - and _", env)
+	}
+
+	exists, err := tctlConfig.HasEnvironment(env)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("environment %q already exists", env)
+	}
+
+	props := map[string]string{
+		FlagAddress: localHostPort,
+	}
+	for _, key := range envKeys {
+		if !c.IsSet(key) {
+			continue
+		}
+
+		val, err := envFlagValue(c, key)
+		if err != nil {
+			return err
+		}
+		props[key] = val
+	}
+
+	for key, val := range props {
+		if err := tctlConfig.Set(c, envConfigKey(env, key), val); err != nil {
+			return fmt.Errorf("unable to create environment %s: %s", env, err)
+		}
+	}
+
+	fmt.Printf("created environment %v\n", color.Magenta(c, "%v", env))
+	return nil
+}
+
+// DeleteEnvironment deletes an environment. The active environment can't be deleted.
+func DeleteEnvironment(c *cli.Context) error {
+	env := c.Args().First()
+	if env == "" {
+		return fmt.Errorf("environment name is required")
+	}
+
+	active, _ := tctlConfig.Get(c, config.KeyActive)
+	if env == active {
+		return fmt.Errorf("unable to delete active environment %s, activate another environment first", env)
+	}
+
+	if err := tctlConfig.DeleteEnvironment(env); err != nil {
+		return fmt.Errorf("unable to delete environment: %s", err)
+	}
+
+	fmt.Printf("deleted environment %v\n", color.Magenta(c, "%v", env))
+	return nil
+}
+
+// UseEnvironment activates an existing environment
+func UseEnvironment(c *cli.Context) error {
+	env := c.Args().First()
+	if env == "" {
+		return fmt.Errorf("environment name is required")
+	}
+
+	exists, err := tctlConfig.HasEnvironment(env)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("environment %q does not exist, create it with 'tctl config env create %s'", env, env)
+	}
+
+	if err := tctlConfig.Set(c, config.KeyActive, env); err != nil {
+		return fmt.Errorf("unable to activate environment %s: %s", env, err)
+	}
+
+	fmt.Printf("%v: %v\n", color.Magenta(c, "%v", config.KeyActive), env)
+	return nil
+}
+
+// exportedConfig is the format of 'config export' and 'config import'
+type exportedConfig struct {
+	Environments map[string]map[string]string `yaml:"environments"`
+}
+
+// ExportConfig prints an environment as YAML
+func ExportConfig(c *cli.Context) error {
+	env := selectedEnvironment(c)
+	props, err := tctlConfig.Environment(env)
+	if err != nil {
+		return err
+	}
+
+	includeSecrets := c.Bool(FlagIncludeSecrets)
+	exported := make(map[string]string, len(props))
+	for key, val := range props {
+		if val == "" {
+			continue
+		}
+		if !includeSecrets && redactConfigValue(key, val) != val {
+			fmt.Fprintf(os.Stderr, "%s %s is stored in plaintext and was not exported, use --%s to include it\n",
+				color.Yellow(c, "Warning:"), key, FlagIncludeSecrets)
+			continue
+		}
+		exported[key] = val
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	err = enc.Encode(exportedConfig{
+		Environments: map[string]map[string]string{env: exported},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to export environment: %s", err)
+	}
+
+	fmt.Print(buf.String())
+	return nil
+}
+
+// ImportConfig imports environments from a YAML file, or from stdin if the file is -
+func ImportConfig(c *cli.Context) error {
+	path := c.Args().First()
+	if path == "" {
+		return fmt.Errorf("file to import is required")
+	}
+
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read %s: %s", path, err)
+	}
+
+	var imported exportedConfig
+	if err := yaml.Unmarshal(data, &imported); err != nil {
+		return fmt.Errorf("unable to parse %s: %s", path, err)
+	}
+	if len(imported.Environments) == 0 {
+		return fmt.Errorf("%s contains no environments", path)
+	}
+
+	// validate everything before writing, so that a bad file doesn't leave the config half imported
+	for env, props := range imported.Environments {
+		if !envNameRegexp.MatchString(env) {
+			return fmt.Errorf("invalid environment name %q: must consist of lowercase letters, digits, - and _", env)
+		}
+
+		exists, err := tctlConfig.HasEnvironment(env)
+		if err != nil {
+			return err
+		}
+		if exists && !c.Bool(FlagOverwrite) {
+			return fmt.Errorf("environment %q already exists, use --%s to replace it", env, FlagOverwrite)
+		}
+
+		for key, val := range props {
+			if !isEnvKey(key) {
+				return fmt.Errorf("invalid property %s of environment %s", key, env)
+			}
+			if err := checkConfigReference(key, val); err != nil {
+				return fmt.Errorf("invalid property %s of environment %s: %s", key, env, err)
+			}
+			if key == FlagGRPCMeta {
+				if _, err := parseGRPCMeta(strings.Split(val, ",")); err != nil {
+					return fmt.Errorf("invalid property %s of environment %s: %s", key, env, err)
+				}
+			}
+		}
+	}
+
+	envs := make([]string, 0, len(imported.Environments))
+	for env := range imported.Environments {
+		envs = append(envs, env)
+	}
+	sort.Strings(envs)
+
+	// references read files and run commands on later invocations, so they must be reviewed
+	var refs []string
+	for _, env := range envs {
+		props := imported.Environments[env]
+		for _, key := range envKeys {
+			if val := props[key]; isSecretKey(key) && isSecretReference(val) {
+				refs = append(refs, fmt.Sprintf("  %s.%s: %s", env, key, val))
+			}
+		}
+	}
+	if len(refs) > 0 && !c.Bool(FlagYes) {
+		fmt.Printf("%s contains references that tctl resolves when the environment is used:\n%s\n", path, strings.Join(refs, "\n"))
+		if path == "-" {
+			return fmt.Errorf("unable to confirm the references when importing from stdin, use --%s to import them", FlagYes)
+		}
+		confirmed, err := promptYes()
+		if err != nil {
+			return fmt.Errorf("failed to get confirmation to import: %s", err)
+		}
+		if !confirmed {
+			fmt.Println("Config is not imported")
+			return nil
+		}
+	}
+
+	for _, env := range envs {
+		props := imported.Environments[env]
+		for _, key := range envKeys {
+			val, ok := props[key]
+			if !ok {
+				// clear properties that are not part of the imported environment
+				if cVal, _ := tctlConfig.Get(c, envConfigKey(env, key)); cVal == "" {
+					continue
+				}
+			}
+
+			if err := tctlConfig.Set(c, envConfigKey(env, key), val); err != nil {
+				return fmt.Errorf("unable to import environment %s: %s", env, err)
+			}
+		}
+
+		fmt.Printf("imported environment %v\n", color.Magenta(c, "%v", env))
+	}
+
+	return nil
+}
+
+// loadEnvironment applies the properties of the selected environment to the global flags
+// that were neither passed explicitly nor set through environment variables
+func loadEnvironment(c *cli.Context) error {
+	if tctlConfig == nil {
+		return nil
+	}
+
+	env := selectedEnvironment(c)
+	if c.IsSet(FlagEnv) {
+		exists, err := tctlConfig.HasEnvironment(env)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("environment %q does not exist", env)
+		}
+	}
+
+	for _, key := range envKeys {
+		if c.IsSet(key) {
+			continue
+		}
+
+		val, _ := tctlConfig.Get(c, envConfigKey(env, key))
+		if val == "" || isSecretKey(key) && isSecretReference(val) {
+			// secret references are resolved by readFlagOrConfig when the value is used
+			continue
+		}
+		if err := checkConfigReference(key, val); err != nil {
+			return fmt.Errorf("invalid value of %s in environment %s: %s", key, env, err)
+		}
+
+		values := []string{val}
+		if key == FlagGRPCMeta {
+			values = strings.Split(val, ",")
+		}
+		for _, v := range values {
+			if err := c.Set(key, v); err != nil {
+				return fmt.Errorf("invalid value of %s in environment %s: %s", key, env, err)
+			}
+		}
 	}
 
 	return nil
 }
 
+// selectedEnvironment returns the environment passed with --env, or the active environment
+func selectedEnvironment(c *cli.Context) string {
+	// --env can be a global flag or a flag of the command
+	for _, ctx := range c.Lineage() {
+		if env := ctx.String(FlagEnv); env != "" {
+			return env
+		}
+	}
+
+	if tctlConfig != nil {
+		if active, _ := tctlConfig.Get(c, config.KeyActive); active != "" {
+			return active
+		}
+	}
+
+	return defaultEnvironment
+}
+
+func envConfigKey(env, key string) string {
+	return fmt.Sprintf("%s.%s.%s", config.KeyEnvironment, env, key)
+}
+
+// envFlagValue returns the value of the flag in the form it is stored in the config
+func envFlagValue(c *cli.Context, key string) (string, error) {
+	if key != FlagGRPCMeta {
+		val := c.String(key)
+		if err := checkConfigReference(key, val); err != nil {
+			return "", fmt.Errorf("invalid %s: %s", key, err)
+		}
+		return val, nil
+	}
+
+	values := c.StringSlice(key)
+	if _, err := parseGRPCMeta(values); err != nil {
+		return "", err
+	}
+	return strings.Join(values, ","), nil
+}
+
 func newAliasCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "alias",
 		Usage: "Create an alias for command",
+		UsageText: "tctl alias --command <name> --alias <command line>\n\n" +
+			"Example: tctl alias --command wfd --alias 'workflow describe --workflow-id $1'\n" +
+			"         tctl wfd my-workflow-id",
+		Description: "The alias value is split into arguments like a shell command line.\n" +
+			"$1..$9 are replaced with the arguments passed to the alias and $@ with all of them.\n" +
+			"Without placeholders, the passed arguments are appended to the alias.",
 		Flags: []cli.Flag{
 			&cli.StringFlag{
-				Name:     "command",
-				Usage:    "New command name",
-				Required: true,
-				Value:    "mycommand",
+				Name:  "command",
+				Usage: "New command name, e.g. mycommand",
 			},
 			&cli.StringFlag{
-				Name:     "alias",
-				Usage:    "Alias for command",
-				Required: true,
-				Value:    "workflow list --output json",
+				Name:  "alias",
+				Usage: "Alias for command, e.g. \"workflow list --output json\"",
 			},
 		},
 		Action: func(c *cli.Context) error {
+			if !c.IsSet("command") && !c.IsSet("alias") {
+				return cli.ShowAppHelp(c)
+			}
 			return createAlias(c)
 		},
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List aliases",
+				Flags: flags.FlagsForRendering,
+				Action: func(c *cli.Context) error {
+					return ListAliases(c)
+				},
+			},
+			{
+				Name:      "delete",
+				Usage:     "Delete an alias",
+				ArgsUsage: "<name>",
+				Action: func(c *cli.Context) error {
+					return DeleteAlias(c)
+				},
+			},
+		},
 	}
 }
 
@@ -251,13 +757,78 @@ func isEnvKey(key string) bool {
 func createAlias(c *cli.Context) error {
 	command := c.String("command")
 	alias := c.String("alias")
+	if command == "" || alias == "" {
+		return fmt.Errorf("both --command and --alias are required")
+	}
+	if rootApp(c).Command(command) != nil {
+		return fmt.Errorf("%s is a built-in command", command)
+	}
+	if _, err := splitCommandLine(alias); err != nil {
+		return fmt.Errorf("invalid alias: %s", err)
+	}
 
-	fullKey := fmt.Sprintf("%s.%s", config.KeyAlias, command)
-
-	if err := tctlConfig.Set(c, fullKey, alias); err != nil {
+	if err := tctlConfig.SetAlias(command, alias); err != nil {
 		return fmt.Errorf("unable to set property %s: %s", config.KeyAlias, err)
 	}
 
 	fmt.Printf("%v: %v\n", color.Magenta(c, "%v", config.KeyAlias), alias)
 	return nil
 }
+
+// ListAliases prints the command aliases
+func ListAliases(c *cli.Context) error {
+	aliases, err := tctlConfig.GetAliases()
+	if err != nil {
+		return fmt.Errorf("unable to list aliases: %s", err)
+	}
+
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	type aliasItem struct {
+		Name  string
+		Alias string
+	}
+	var items []interface{}
+	for _, name := range names {
+		items = append(items, aliasItem{Name: name, Alias: aliases[name]})
+	}
+
+	opts := &output.PrintOptions{
+		Fields:  []string{"Name", "Alias"},
+		NoPager: true,
+	}
+	output.PrintItems(c, items, opts)
+
+	return nil
+}
+
+// DeleteAlias deletes a command alias
+func DeleteAlias(c *cli.Context) error {
+	name := c.Args().First()
+	if name == "" {
+		return fmt.Errorf("alias name is required")
+	}
+
+	if err := tctlConfig.DeleteAlias(name); err != nil {
+		return fmt.Errorf("unable to delete alias: %s", err)
+	}
+
+	fmt.Printf("deleted alias %v\n", color.Magenta(c, "%v", name))
+	return nil
+}
+
+// rootApp returns the tctl app. Commands with subcommands run as separate apps, so c.App may be one of them.
+func rootApp(c *cli.Context) *cli.App {
+	app := c.App
+	for _, ctx := range c.Lineage() {
+		if ctx.App != nil {
+			app = ctx.App
+		}
+	}
+
+	return app
+}
@@ -0,0 +1,98 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/temporalio/tctl/config"
+)
+
+func TestValidateAliasTarget(t *testing.T) {
+	commands := NewCliApp().Commands
+
+	require.NoError(t, validateAliasTarget(commands, []string{"workflow", "list", "--output", "json"}))
+	require.NoError(t, validateAliasTarget(commands, []string{"w", "describe", "-w", "wid"}))
+	require.Error(t, validateAliasTarget(commands, []string{"workflow", "lsit"}))
+	require.Error(t, validateAliasTarget(commands, []string{"nope"}))
+	require.Error(t, validateAliasTarget(commands, []string{"--output", "json"}))
+}
+
+func TestNewEnvFlags(t *testing.T) {
+	var names []string
+	for _, f := range newEnvFlags() {
+		names = append(names, f.Names()[0])
+	}
+
+	require.ElementsMatch(t, envKeys, names)
+}
+
+func TestEnvFlagValue_GRPCMeta(t *testing.T) {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.Var(cli.NewStringSlice("tenant=acme", "x-trace=a=1"), FlagGRPCMeta, "")
+	val, err := envFlagValue(cli.NewContext(nil, set, nil), FlagGRPCMeta)
+	require.NoError(t, err)
+	require.Equal(t, "tenant=acme,x-trace=a=1", val)
+
+	set = flag.NewFlagSet("test", flag.ContinueOnError)
+	set.Var(cli.NewStringSlice("tracestate=a=1,b=2"), FlagGRPCMeta, "")
+	_, err = envFlagValue(cli.NewContext(nil, set, nil), FlagGRPCMeta)
+	require.Error(t, err)
+}
+
+func (s *cliAppSuite) TestImportConfig_ConfirmReferences() {
+	s.T().Setenv("HOME", s.T().TempDir())
+	defer func(cfg *config.Config) { tctlConfig = cfg }(tctlConfig)
+	var err error
+	tctlConfig, err = config.NewTctlConfig()
+	s.NoError(err)
+
+	file := filepath.Join(s.T().TempDir(), "config.yaml")
+	s.NoError(os.WriteFile(file, []byte("environments:\n  shared:\n    address: shared:7233\n    auth: exec:get-token\n"), 0600))
+
+	// declined
+	stdin, err := os.CreateTemp(s.T().TempDir(), "stdin")
+	s.NoError(err)
+	_, err = stdin.WriteString("no\n")
+	s.NoError(err)
+	_, err = stdin.Seek(0, 0)
+	s.NoError(err)
+	defer func(f *os.File) { os.Stdin = f }(os.Stdin)
+	os.Stdin = stdin
+
+	s.NoError(s.app.Run([]string{"", "config", "import", file}))
+	exists, err := tctlConfig.HasEnvironment("shared")
+	s.NoError(err)
+	s.False(exists)
+
+	s.NoError(s.app.Run([]string{"", "config", "import", "--yes", file}))
+	props, err := tctlConfig.Environment("shared")
+	s.NoError(err)
+	s.Equal("exec:get-token", props[FlagAuth])
+}
@@ -0,0 +1,257 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/temporalio/tctl-kit/pkg/color"
+)
+
+const addressLookupTimeout = 5 * time.Second
+
+var aliasPlaceholders = []string{"$1", "$2", "$3", "$4", "$5", "$6", "$7", "$8", "$9"}
+
+// ValidateConfig checks that the properties of an environment are usable and that aliases point to commands
+func ValidateConfig(c *cli.Context) error {
+	env := selectedEnvironment(c)
+	props, err := tctlConfig.Environment(env)
+	if err != nil {
+		return err
+	}
+
+	v := &configValidator{c: c, props: props}
+	fmt.Printf("environment %v:\n", color.Magenta(c, "%v", env))
+	v.validateSecrets()
+	v.validateTLS()
+	v.validateAddress()
+
+	aliases, err := tctlConfig.GetAliases()
+	if err != nil {
+		return fmt.Errorf("unable to read aliases: %s", err)
+	}
+	if len(aliases) > 0 {
+		fmt.Println("aliases:")
+		v.validateAliases(aliases)
+	}
+
+	if v.problems > 0 {
+		return fmt.Errorf("found %d problem(s) in config", v.problems)
+	}
+	return nil
+}
+
+type configValidator struct {
+	c        *cli.Context
+	props    map[string]string
+	problems int
+}
+
+func (v *configValidator) ok(format string, a ...interface{}) {
+	fmt.Printf("  %s %s\n", color.Green(v.c, "OK  "), fmt.Sprintf(format, a...))
+}
+
+func (v *configValidator) fail(format string, a ...interface{}) {
+	v.problems++
+	fmt.Printf("  %s %s\n", color.Red(v.c, "FAIL"), fmt.Sprintf(format, a...))
+}
+
+func (v *configValidator) skip(format string, a ...interface{}) {
+	fmt.Printf("  %s %s\n", color.Yellow(v.c, "SKIP"), fmt.Sprintf(format, a...))
+}
+
+// validateSecrets resolves the secret references of secret keys. Commands of exec: references are not run,
+// as they can prompt the user or have side effects.
+func (v *configValidator) validateSecrets() {
+	keys := make([]string, 0, len(v.props))
+	for key := range v.props {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		val := v.props[key]
+		if err := checkConfigReference(key, val); err != nil {
+			v.fail("%s: %s", key, err)
+			continue
+		}
+		if !isSecretKey(key) || !isSecretReference(val) {
+			continue
+		}
+
+		if strings.HasPrefix(val, secretRefExec) {
+			v.skip("%s: %s runs a command, it is not run by validate", key, val)
+		} else if _, err := resolveSecret(val); err != nil {
+			v.fail("%s: unable to resolve %s: %s", key, val, err)
+		} else {
+			v.ok("%s: %s resolves", key, val)
+		}
+	}
+}
+
+func (v *configValidator) validateTLS() {
+	certPath := v.props[FlagTLSCertPath]
+	keyPath := v.props[FlagTLSKeyPath]
+	caPath := v.props[FlagTLSCaPath]
+	serverName := v.props[FlagTLSServerName]
+	if certPath == "" && keyPath == "" && caPath == "" && serverName == "" {
+		return
+	}
+
+	valid := true
+	for _, key := range []string{FlagTLSCertPath, FlagTLSKeyPath, FlagTLSCaPath} {
+		path := v.props[key]
+		if path == "" || strings.HasPrefix(path, "https://") {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			v.fail("%s: %s", key, err)
+			valid = false
+		}
+	}
+	if (certPath == "") != (keyPath == "") {
+		v.fail("%s and %s must be set together", FlagTLSCertPath, FlagTLSKeyPath)
+		valid = false
+	}
+
+	disableHostNameVerification := false
+	if val := v.props[FlagTLSDisableHostVerification]; val != "" {
+		var err error
+		if disableHostNameVerification, err = strconv.ParseBool(val); err != nil {
+			v.fail("%s: %s", FlagTLSDisableHostVerification, err)
+			valid = false
+		}
+	}
+	if !valid {
+		return
+	}
+
+	_, err := newTLSConfig(tlsOptions{
+		certPath:                    certPath,
+		keyPath:                     keyPath,
+		caPath:                      caPath,
+		disableHostNameVerification: disableHostNameVerification,
+		serverName:                  serverName,
+		hostPort:                    v.props[FlagAddress],
+	})
+	if err != nil {
+		v.fail("TLS: %s", err)
+	} else {
+		v.ok("TLS certificates load")
+	}
+}
+
+func (v *configValidator) validateAddress() {
+	hostPort := v.props[FlagAddress]
+	if hostPort == "" {
+		hostPort = localHostPort
+	}
+
+	host, _, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		v.fail("%s: %s", FlagAddress, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), addressLookupTimeout)
+	defer cancel()
+	if _, err := net.DefaultResolver.LookupHost(ctx, host); err != nil {
+		v.fail("%s: unable to resolve %s: %s", FlagAddress, host, err)
+		return
+	}
+
+	v.ok("%s: %s resolves", FlagAddress, host)
+}
+
+func (v *configValidator) validateAliases(aliases map[string]string) {
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		app := rootApp(v.c)
+		if app.Command(name) != nil {
+			v.fail("%s: alias is shadowed by a built-in command", name)
+			continue
+		}
+
+		// placeholders stand in for the arguments passed to the alias
+		args, err := resolveAlias(app, name, aliasPlaceholders)
+		if err == nil {
+			err = validateAliasTarget(app.Commands, args)
+		}
+		if err != nil {
+			v.fail("%s: %s", name, err)
+		} else {
+			v.ok("%s: %s", name, aliases[name])
+		}
+	}
+}
+
+// validateAliasTarget checks that the arguments start with a command and that all subcommands exist
+func validateAliasTarget(commands []*cli.Command, args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf("alias does not start with a command")
+	}
+
+	cmd := findCommand(commands, args[0])
+	if cmd == nil {
+		return fmt.Errorf("%s is not a command", args[0])
+	}
+
+	for _, arg := range args[1:] {
+		// flags and the arguments passed to the alias end the command
+		if strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "$") || len(cmd.Subcommands) == 0 {
+			break
+		}
+
+		sub := findCommand(cmd.Subcommands, arg)
+		if sub == nil {
+			return fmt.Errorf("%s is not a subcommand of %s", arg, cmd.Name)
+		}
+		cmd = sub
+	}
+
+	return nil
+}
+
+func findCommand(commands []*cli.Command, name string) *cli.Command {
+	for _, cmd := range commands {
+		if cmd.HasName(name) {
+			return cmd
+		}
+	}
+
+	return nil
+}
@@ -25,6 +25,7 @@ package dataconverter
 import (
 	"net/http"
 	"strings"
+	"sync"
 
 	"go.temporal.io/sdk/converter"
 )
@@ -38,14 +39,34 @@ func SetCurrent(dc converter.DataConverter) {
 }
 
 func SetRemoteEndpoint(endpoint string, namespace string, auth string) {
+	SetRemoteEndpointWithAuth(endpoint, namespace, func() (string, error) {
+		return auth, nil
+	})
+}
+
+// SetRemoteEndpointWithAuth is like SetRemoteEndpoint, but the authorization header
+// is only obtained when the first request is made to the codec server
+func SetRemoteEndpointWithAuth(endpoint string, namespace string, getAuth func() (string, error)) {
 	endpoint = strings.ReplaceAll(endpoint, "{namespace}", namespace)
 
+	var (
+		authOnce sync.Once
+		auth     string
+		authErr  error
+	)
 	dataConverter = converter.NewRemoteDataConverter(
 		converter.GetDefaultDataConverter(),
 		converter.RemoteDataConverterOptions{
 			Endpoint: endpoint,
 			ModifyRequest: func(req *http.Request) error {
 				req.Header.Set("X-Namespace", namespace)
+
+				authOnce.Do(func() {
+					auth, authErr = getAuth()
+				})
+				if authErr != nil {
+					return authErr
+				}
 				if auth != "" {
 					req.Header.Set("Authorization", auth)
 				}
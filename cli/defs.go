@@ -28,8 +28,9 @@ import (
 	"time"
 
 	"github.com/olekukonko/tablewriter"
-	"github.com/temporalio/tctl-kit/pkg/config"
 	enumspb "go.temporal.io/api/enums/v1"
+
+	"github.com/temporalio/tctl/config"
 )
 
 const (
@@ -63,11 +64,36 @@ const (
 	defaultPageSizeForScan              = 2000
 	defaultWorkflowIDReusePolicy        = enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE
 	defaultPageSizeDLQ                  = 1000
+	defaultStackTraceConcurrency        = 10
+	defaultStackTraceLimit              = 1000
+	// a worker long polls for about a minute, so a poller that hasn't polled for longer is likely gone
+	defaultStalePollerSeconds = 120
+	defaultWorkflowTreeDepth  = 5
+	// thresholds of "workflow find-stuck", the server fails workflows at 50K history events by default
+	defaultFindStuckRPS                     = 50
+	defaultFindStuckMaxWorkflowTaskAttempts = 3
+	defaultFindStuckMaxActivityAttempts     = 10
+	defaultFindStuckNoProgress              = "24h"
+	defaultFindStuckHistoryLength           = 40000
+	// terminations of "workflow terminate --input-file" per second
+	defaultTerminateRPS = 50
 
 	workflowStatusNotSet = -1
 	showErrorStackEnv    = `TEMPORAL_CLI_SHOW_STACKS`
 
 	searchAttrInputSeparator = "|"
+
+	// exit codes of "workflow wait" and "workflow result", 1 is used for all other errors
+	exitCodeWorkflowFailed         = 2
+	exitCodeWorkflowTimedOut       = 3
+	exitCodeWorkflowCanceled       = 4
+	exitCodeWorkflowTerminated     = 5
+	exitCodeWorkflowContinuedAsNew = 6
+	exitCodeWaitTimedOut           = 7
+
+	// built-in query types handled by the SDKs
+	stackTraceQueryType       = "__stack_trace"
+	workflowMetadataQueryType = "__temporal_workflow_metadata"
 )
 
 var envKeysForUserName = []string{
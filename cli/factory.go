@@ -44,10 +44,12 @@ import (
 	"go.temporal.io/api/workflowservice/v1"
 	sdkclient "go.temporal.io/sdk/client"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/metadata"
+	grpcstatus "google.golang.org/grpc/status"
 
 	"go.temporal.io/server/common/auth"
 	"go.temporal.io/server/common/log"
@@ -65,9 +67,9 @@ type HttpGetter interface {
 
 // ClientFactory is used to construct rpc clients
 type ClientFactory interface {
-	FrontendClient(c *cli.Context) workflowservice.WorkflowServiceClient
+	FrontendClient(c *cli.Context) (workflowservice.WorkflowServiceClient, error)
 	SDKClient(c *cli.Context, namespace string) sdkclient.Client
-	HealthClient(c *cli.Context) healthpb.HealthClient
+	HealthClient(c *cli.Context) (healthpb.HealthClient, error)
 }
 
 type clientFactory struct {
@@ -84,10 +86,13 @@ func NewClientFactory() ClientFactory {
 }
 
 // FrontendClient builds a frontend client
-func (b *clientFactory) FrontendClient(c *cli.Context) workflowservice.WorkflowServiceClient {
-	connection, _ := b.createGRPCConnection(c)
+func (b *clientFactory) FrontendClient(c *cli.Context) (workflowservice.WorkflowServiceClient, error) {
+	connection, err := b.createGRPCConnection(c)
+	if err != nil {
+		return nil, err
+	}
 
-	return workflowservice.NewWorkflowServiceClient(connection)
+	return workflowservice.NewWorkflowServiceClient(connection), nil
 }
 
 // SDKClient builds an SDK client.
@@ -102,15 +107,26 @@ func (b *clientFactory) SDKClient(c *cli.Context, namespace string) sdkclient.Cl
 		b.logger.Fatal("Failed to configure TLS for SDK client", tag.Error(err))
 	}
 
+	var dialOpts []grpc.DialOption
+	headersProvider := headersprovider.GetCurrent()
+	if headersProvider != nil {
+		// Headers are set by the CLI interceptors instead of sdkclient.Options.HeadersProvider
+		// so that requests rejected with expired credentials are retried with fresh headers
+		dialOpts = append(dialOpts,
+			grpc.WithChainUnaryInterceptor(headersProviderInterceptor(headersProvider)),
+			grpc.WithChainStreamInterceptor(headersProviderStreamInterceptor(headersProvider)),
+		)
+	}
+
 	sdkClient, err := sdkclient.NewClient(sdkclient.Options{
 		HostPort:  hostPort,
 		Namespace: namespace,
 		Logger:    log.NewSdkLogger(b.logger),
 		Identity:  getCliIdentity(),
 		ConnectionOptions: sdkclient.ConnectionOptions{
-			TLS: tlsConfig,
+			TLS:         tlsConfig,
+			DialOptions: dialOpts,
 		},
-		HeadersProvider: headersprovider.GetCurrent(),
 	})
 	if err != nil {
 		b.logger.Fatal("Failed to create SDK client", tag.Error(err))
@@ -120,25 +136,78 @@ func (b *clientFactory) SDKClient(c *cli.Context, namespace string) sdkclient.Cl
 }
 
 // HealthClient builds a health client.
-func (b *clientFactory) HealthClient(c *cli.Context) healthpb.HealthClient {
-	connection, _ := b.createGRPCConnection(c)
+func (b *clientFactory) HealthClient(c *cli.Context) (healthpb.HealthClient, error) {
+	connection, err := b.createGRPCConnection(c)
+	if err != nil {
+		return nil, err
+	}
 
-	return healthpb.NewHealthClient(connection)
+	return healthpb.NewHealthClient(connection), nil
 }
 
+// headersProviderInterceptor sets the headers returned by the headers provider on every request.
+// A request rejected as unauthenticated is retried once with refreshed headers, so that long polls
+// and other long running commands survive a credentials rotation.
 func headersProviderInterceptor(headersProvider plugin.HeadersProvider) grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-		headers, err := headersProvider.GetHeaders(ctx)
+		outCtx, err := withProviderHeaders(ctx, headersProvider)
 		if err != nil {
 			return err
 		}
-		for k, v := range headers {
-			ctx = metadata.AppendToOutgoingContext(ctx, k, v)
+
+		err = invoker(outCtx, method, req, reply, cc, opts...)
+		if grpcstatus.Code(err) != codes.Unauthenticated {
+			return err
 		}
-		return invoker(ctx, method, req, reply, cc, opts...)
+
+		headersprovider.Invalidate(headersProvider)
+		outCtx, hErr := withProviderHeaders(ctx, headersProvider)
+		if hErr != nil {
+			return err
+		}
+		return invoker(outCtx, method, req, reply, cc, opts...)
 	}
 }
 
+// headersProviderStreamInterceptor is the streaming counterpart of headersProviderInterceptor.
+// Only opening the stream is retried, as messages may already have been exchanged afterwards.
+func headersProviderStreamInterceptor(headersProvider plugin.HeadersProvider) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		outCtx, err := withProviderHeaders(ctx, headersProvider)
+		if err != nil {
+			return nil, err
+		}
+
+		stream, err := streamer(outCtx, desc, cc, method, opts...)
+		if grpcstatus.Code(err) != codes.Unauthenticated {
+			return stream, err
+		}
+
+		headersprovider.Invalidate(headersProvider)
+		outCtx, hErr := withProviderHeaders(ctx, headersProvider)
+		if hErr != nil {
+			return nil, err
+		}
+		return streamer(outCtx, desc, cc, method, opts...)
+	}
+}
+
+// withProviderHeaders returns a context with the provider headers set as outgoing metadata.
+// Existing values of the same keys are replaced rather than appended to, so that retries don't send stale credentials.
+func withProviderHeaders(ctx context.Context, headersProvider plugin.HeadersProvider) (context.Context, error) {
+	headers, err := headersProvider.GetHeaders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	for k, v := range headers {
+		md.Set(k, v)
+	}
+	return metadata.NewOutgoingContext(ctx, md), nil
+}
+
 func (b *clientFactory) createGRPCConnection(c *cli.Context) (*grpc.ClientConn, error) {
 	hostPort := readFlagOrConfig(c, FlagAddress)
 	if hostPort == "" {
@@ -147,7 +216,7 @@ func (b *clientFactory) createGRPCConnection(c *cli.Context) (*grpc.ClientConn,
 
 	tlsConfig, err := b.createTLSConfig(c)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("unable to configure TLS: %s", err)
 	}
 
 	grpcSecurityOptions := grpc.WithTransportCredentials(insecure.NewCredentials())
@@ -161,62 +230,78 @@ func (b *clientFactory) createGRPCConnection(c *cli.Context) (*grpc.ClientConn,
 	}
 	headersProvider := headersprovider.GetCurrent()
 	if headersProvider != nil {
-		dialOpts = append(dialOpts, grpc.WithUnaryInterceptor(headersProviderInterceptor(headersProvider)))
+		dialOpts = append(dialOpts,
+			grpc.WithUnaryInterceptor(headersProviderInterceptor(headersProvider)),
+			grpc.WithStreamInterceptor(headersProviderStreamInterceptor(headersProvider)),
+		)
 	}
 
 	connection, err := grpc.Dial(hostPort, dialOpts...)
 	if err != nil {
-		b.logger.Fatal("Failed to create connection", tag.Error(err))
-		return nil, err
+		return nil, fmt.Errorf("unable to create connection: %s", err)
 	}
 	return connection, nil
 }
 
 func (b *clientFactory) createTLSConfig(c *cli.Context) (*tls.Config, error) {
-	certPath := readFlagOrConfig(c, FlagTLSCertPath)
-	keyPath := readFlagOrConfig(c, FlagTLSKeyPath)
-	caPath := readFlagOrConfig(c, FlagTLSCaPath)
 	disableHostNameVerificationS := readFlagOrConfig(c, FlagTLSDisableHostVerification)
 	disableHostNameVerification, err := strconv.ParseBool(disableHostNameVerificationS)
 	if err != nil {
 		return nil, fmt.Errorf("unable to read TLS disable host verification flag: %s", err)
 	}
 
-	serverName := readFlagOrConfig(c, FlagTLSServerName)
+	return newTLSConfig(tlsOptions{
+		certPath:                    readFlagOrConfig(c, FlagTLSCertPath),
+		keyPath:                     readFlagOrConfig(c, FlagTLSKeyPath),
+		caPath:                      readFlagOrConfig(c, FlagTLSCaPath),
+		disableHostNameVerification: disableHostNameVerification,
+		serverName:                  readFlagOrConfig(c, FlagTLSServerName),
+		hostPort:                    readFlagOrConfig(c, FlagAddress),
+	})
+}
+
+type tlsOptions struct {
+	certPath                    string
+	keyPath                     string
+	caPath                      string
+	disableHostNameVerification bool
+	serverName                  string
+	hostPort                    string
+}
 
+// newTLSConfig returns the TLS config for the options, or nil if TLS is not configured
+func newTLSConfig(opts tlsOptions) (*tls.Config, error) {
 	var host string
 	var cert *tls.Certificate
 	var caPool *x509.CertPool
 
-	if caPath != "" {
-		caCertPool, err := fetchCACert(caPath)
+	if opts.caPath != "" {
+		caCertPool, err := fetchCACert(opts.caPath)
 		if err != nil {
-			b.logger.Fatal("Failed to load server CA certificate", tag.Error(err))
-			return nil, err
+			return nil, fmt.Errorf("failed to load server CA certificate: %s", err)
 		}
 		caPool = caCertPool
 	}
-	if certPath != "" {
-		myCert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if opts.certPath != "" {
+		myCert, err := tls.LoadX509KeyPair(opts.certPath, opts.keyPath)
 		if err != nil {
-			b.logger.Fatal("Failed to load client certificate", tag.Error(err))
-			return nil, err
+			return nil, fmt.Errorf("failed to load client certificate: %s", err)
 		}
 		cert = &myCert
 	}
 	// If we are given arguments to verify either server or client, configure TLS
 	if caPool != nil || cert != nil {
-		if serverName != "" {
-			host = serverName
+		if opts.serverName != "" {
+			host = opts.serverName
 		} else {
-			hostPort := readFlagOrConfig(c, FlagAddress)
+			hostPort := opts.hostPort
 			if hostPort == "" {
 				hostPort = localHostPort
 			}
 			// Ignoring error as we'll fail to dial anyway, and that will produce a meaningful error
 			host, _, _ = net.SplitHostPort(hostPort)
 		}
-		tlsConfig := auth.NewTLSConfigForServer(host, !disableHostNameVerification)
+		tlsConfig := auth.NewTLSConfigForServer(host, !opts.disableHostNameVerification)
 		if caPool != nil {
 			tlsConfig.RootCAs = caPool
 		}
@@ -227,9 +312,9 @@ func (b *clientFactory) createTLSConfig(c *cli.Context) (*tls.Config, error) {
 		return tlsConfig, nil
 	}
 	// If we are given a server name, set the TLS server name for DNS resolution
-	if serverName != "" {
-		host = serverName
-		tlsConfig := auth.NewTLSConfigForServer(host, !disableHostNameVerification)
+	if opts.serverName != "" {
+		host = opts.serverName
+		tlsConfig := auth.NewTLSConfigForServer(host, !opts.disableHostNameVerification)
 		return tlsConfig, nil
 	}
 
@@ -25,13 +25,24 @@
 package cli
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	grpcstatus "google.golang.org/grpc/status"
 )
 
 type args struct {
@@ -144,3 +155,78 @@ func Test_fetchCACertFromFile(t *testing.T) {
 		})
 	}
 }
+
+type rotatingHeadersProvider struct {
+	calls       int
+	invalidated int
+}
+
+func (p *rotatingHeadersProvider) GetHeaders(_ context.Context) (map[string]string, error) {
+	p.calls++
+	return map[string]string{"authorization": fmt.Sprintf("token-%d", p.calls)}, nil
+}
+
+func (p *rotatingHeadersProvider) Invalidate() {
+	p.invalidated++
+}
+
+func Test_headersProviderInterceptor(t *testing.T) {
+	provider := &rotatingHeadersProvider{}
+	interceptor := headersProviderInterceptor(provider)
+
+	var sent [][]string
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		sent = append(sent, md.Get("authorization"))
+		if len(sent) == 1 {
+			return grpcstatus.Error(codes.Unauthenticated, "token expired")
+		}
+		return nil
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "stale")
+	if err := interceptor(ctx, "/test", nil, nil, nil, invoker); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sent) != 2 {
+		t.Fatalf("expected a single retry, got %d calls", len(sent))
+	}
+	if len(sent[0]) != 1 || sent[0][0] != "token-1" || len(sent[1]) != 1 || sent[1][0] != "token-2" {
+		t.Errorf("unexpected authorization headers: %v", sent)
+	}
+	if provider.invalidated != 1 {
+		t.Errorf("expected provider to be invalidated once, got %d", provider.invalidated)
+	}
+}
+
+func Test_headersProviderStreamInterceptor(t *testing.T) {
+	provider := &rotatingHeadersProvider{}
+	interceptor := headersProviderStreamInterceptor(provider)
+
+	calls := 0
+	streamer := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		calls++
+		return nil, grpcstatus.Error(codes.Unauthenticated, "token expired")
+	}
+
+	_, err := interceptor(context.Background(), &grpc.StreamDesc{}, nil, "/test", streamer)
+	if grpcstatus.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected unauthenticated error, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected a single retry, got %d calls", calls)
+	}
+}
+
+func Test_FrontendClient_TLSError(t *testing.T) {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.String(FlagTLSCertPath, "", "")
+	set.String(FlagTLSKeyPath, "", "")
+	set.String(FlagTLSDisableHostVerification, "", "")
+	require.NoError(t, set.Set(FlagTLSCertPath, filepath.Join(t.TempDir(), "missing.pem")))
+	require.NoError(t, set.Set(FlagTLSKeyPath, filepath.Join(t.TempDir(), "missing.key")))
+	require.NoError(t, set.Set(FlagTLSDisableHostVerification, "false"))
+
+	_, err := NewClientFactory().FrontendClient(cli.NewContext(nil, set, nil))
+	require.ErrorContains(t, err, "unable to configure TLS")
+}
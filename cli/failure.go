@@ -0,0 +1,226 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/temporalio/tctl-kit/pkg/color"
+	"github.com/urfave/cli/v2"
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	failurepb "go.temporal.io/api/failure/v1"
+	historypb "go.temporal.io/api/history/v1"
+)
+
+// failureLevel is one level of the cause chain of a failure
+type failureLevel struct {
+	Type       string `json:"type"`
+	Message    string `json:"message"`
+	Source     string `json:"source,omitempty"`
+	Details    string `json:"details,omitempty"`
+	StackTrace string `json:"stackTrace,omitempty"`
+}
+
+// failureLevels walks the cause chain of a failure, outermost first
+func failureLevels(failure *failurepb.Failure) []failureLevel {
+	var levels []failureLevel
+	for f := failure; f != nil; f = f.GetCause() {
+		levels = append(levels, failureLevel{
+			Type:       failureTypeName(f),
+			Message:    f.GetMessage(),
+			Source:     f.GetSource(),
+			Details:    failureDetails(f),
+			StackTrace: strings.TrimSpace(f.GetStackTrace()),
+		})
+	}
+	return levels
+}
+
+// failureTypeName names the kind of a failure along with what identifies it, such as the activity that failed
+func failureTypeName(f *failurepb.Failure) string {
+	var name string
+	var attrs []string
+	switch info := f.GetFailureInfo().(type) {
+	case *failurepb.Failure_ApplicationFailureInfo:
+		name = "ApplicationFailure"
+		if t := info.ApplicationFailureInfo.GetType(); t != "" {
+			attrs = append(attrs, "type "+t)
+		}
+		if info.ApplicationFailureInfo.GetNonRetryable() {
+			attrs = append(attrs, "non-retryable")
+		}
+	case *failurepb.Failure_TimeoutFailureInfo:
+		name = "TimeoutFailure"
+		attrs = append(attrs, info.TimeoutFailureInfo.GetTimeoutType().String())
+	case *failurepb.Failure_CanceledFailureInfo:
+		name = "CanceledFailure"
+	case *failurepb.Failure_TerminatedFailureInfo:
+		name = "TerminatedFailure"
+	case *failurepb.Failure_ServerFailureInfo:
+		name = "ServerFailure"
+		if info.ServerFailureInfo.GetNonRetryable() {
+			attrs = append(attrs, "non-retryable")
+		}
+	case *failurepb.Failure_ResetWorkflowFailureInfo:
+		name = "ResetWorkflowFailure"
+	case *failurepb.Failure_ActivityFailureInfo:
+		name = "ActivityFailure"
+		attrs = append(attrs,
+			fmt.Sprintf("activity %s %s", info.ActivityFailureInfo.GetActivityType().GetName(), info.ActivityFailureInfo.GetActivityId()),
+			"retry state "+info.ActivityFailureInfo.GetRetryState().String())
+	case *failurepb.Failure_ChildWorkflowExecutionFailureInfo:
+		name = "ChildWorkflowFailure"
+		attrs = append(attrs,
+			fmt.Sprintf("workflow %s %s", info.ChildWorkflowExecutionFailureInfo.GetWorkflowType().GetName(), info.ChildWorkflowExecutionFailureInfo.GetWorkflowExecution().GetWorkflowId()),
+			"retry state "+info.ChildWorkflowExecutionFailureInfo.GetRetryState().String())
+	default:
+		name = "Failure"
+	}
+
+	if len(attrs) == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s (%s)", name, strings.Join(attrs, ", "))
+}
+
+// failureDetails decodes the details attached to a failure with the data converter in use, on a single line
+func failureDetails(f *failurepb.Failure) string {
+	var details *commonpb.Payloads
+	switch info := f.GetFailureInfo().(type) {
+	case *failurepb.Failure_ApplicationFailureInfo:
+		details = info.ApplicationFailureInfo.GetDetails()
+	case *failurepb.Failure_TimeoutFailureInfo:
+		details = info.TimeoutFailureInfo.GetLastHeartbeatDetails()
+	case *failurepb.Failure_CanceledFailureInfo:
+		details = info.CanceledFailureInfo.GetDetails()
+	case *failurepb.Failure_ResetWorkflowFailureInfo:
+		details = info.ResetWorkflowFailureInfo.GetLastHeartbeatDetails()
+	}
+	if len(details.GetPayloads()) == 0 {
+		return ""
+	}
+
+	value := decodePayloads(details)
+	if s, ok := value.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(b)
+}
+
+// eventFailure returns the failure recorded by an event, if any
+func eventFailure(event *historypb.HistoryEvent) *failurepb.Failure {
+	switch event.GetEventType() {
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_FAILED:
+		return event.GetWorkflowExecutionFailedEventAttributes().GetFailure()
+	case enumspb.EVENT_TYPE_WORKFLOW_TASK_FAILED:
+		return event.GetWorkflowTaskFailedEventAttributes().GetFailure()
+	case enumspb.EVENT_TYPE_ACTIVITY_TASK_FAILED:
+		return event.GetActivityTaskFailedEventAttributes().GetFailure()
+	case enumspb.EVENT_TYPE_ACTIVITY_TASK_TIMED_OUT:
+		return event.GetActivityTaskTimedOutEventAttributes().GetFailure()
+	case enumspb.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_FAILED:
+		return event.GetChildWorkflowExecutionFailedEventAttributes().GetFailure()
+	default:
+		return nil
+	}
+}
+
+// eventAttributesWithoutFailure returns a copy of the attributes of an event recording a failure, without the failure
+func eventAttributesWithoutFailure(event *historypb.HistoryEvent) interface{} {
+	switch event.GetEventType() {
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_FAILED:
+		attrs := proto.Clone(event.GetWorkflowExecutionFailedEventAttributes()).(*historypb.WorkflowExecutionFailedEventAttributes)
+		attrs.Failure = nil
+		return attrs
+	case enumspb.EVENT_TYPE_WORKFLOW_TASK_FAILED:
+		attrs := proto.Clone(event.GetWorkflowTaskFailedEventAttributes()).(*historypb.WorkflowTaskFailedEventAttributes)
+		attrs.Failure = nil
+		return attrs
+	case enumspb.EVENT_TYPE_ACTIVITY_TASK_FAILED:
+		attrs := proto.Clone(event.GetActivityTaskFailedEventAttributes()).(*historypb.ActivityTaskFailedEventAttributes)
+		attrs.Failure = nil
+		return attrs
+	case enumspb.EVENT_TYPE_ACTIVITY_TASK_TIMED_OUT:
+		attrs := proto.Clone(event.GetActivityTaskTimedOutEventAttributes()).(*historypb.ActivityTaskTimedOutEventAttributes)
+		attrs.Failure = nil
+		return attrs
+	case enumspb.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_FAILED:
+		attrs := proto.Clone(event.GetChildWorkflowExecutionFailedEventAttributes()).(*historypb.ChildWorkflowExecutionFailedEventAttributes)
+		attrs.Failure = nil
+		return attrs
+	default:
+		return getEventAttributes(event)
+	}
+}
+
+// formatFailure renders a failure and its causes for the terminal, prefixing each line with indent
+func formatFailure(c *cli.Context, failure *failurepb.Failure, indent string) string {
+	return renderFailure(failure, indent, func(title string) string {
+		return color.Red(c, "%s", title)
+	})
+}
+
+// formatFailurePlain renders a failure like formatFailure, without colors
+func formatFailurePlain(failure *failurepb.Failure, indent string) string {
+	return renderFailure(failure, indent, func(title string) string {
+		return title
+	})
+}
+
+func renderFailure(failure *failurepb.Failure, indent string, colorTitle func(string) string) string {
+	var b strings.Builder
+	for i, level := range failureLevels(failure) {
+		title := colorTitle(level.Type)
+		if i > 0 {
+			title = "Caused by " + title
+		}
+		fmt.Fprintf(&b, "%s%s: %s\n", indent, title, level.Message)
+		if level.Source != "" {
+			fmt.Fprintf(&b, "%s  Source: %s\n", indent, level.Source)
+		}
+		if level.Details != "" {
+			fmt.Fprintf(&b, "%s  Details: %s\n", indent, indentLines(level.Details, indent+"    ", false))
+		}
+		if level.StackTrace != "" {
+			fmt.Fprintf(&b, "%s  Stack trace:\n%s\n", indent, indentLines(level.StackTrace, indent+"    ", true))
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// indentLines indents the lines of s, except the first one unless first is set
+func indentLines(s string, indent string, first bool) string {
+	s = strings.ReplaceAll(s, "\n", "\n"+indent)
+	if first {
+		s = indent + s
+	}
+	return s
+}
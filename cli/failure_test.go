@@ -0,0 +1,168 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	failurepb "go.temporal.io/api/failure/v1"
+	historypb "go.temporal.io/api/history/v1"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/server/common/payloads"
+)
+
+func activityFailure() *failurepb.Failure {
+	return &failurepb.Failure{
+		Message: "activity error",
+		FailureInfo: &failurepb.Failure_ActivityFailureInfo{ActivityFailureInfo: &failurepb.ActivityFailureInfo{
+			ActivityType: &commonpb.ActivityType{Name: "Charge"},
+			ActivityId:   "5",
+			RetryState:   enumspb.RETRY_STATE_NON_RETRYABLE_FAILURE,
+		}},
+		Cause: &failurepb.Failure{
+			Message:    "card declined",
+			Source:     "GoSDK",
+			StackTrace: "main.Charge()\n\t/app/activities.go:42\n",
+			FailureInfo: &failurepb.Failure_ApplicationFailureInfo{ApplicationFailureInfo: &failurepb.ApplicationFailureInfo{
+				Type:         "CardDeclined",
+				NonRetryable: true,
+				Details:      payloads.EncodeString("insufficient funds"),
+			}},
+			Cause: &failurepb.Failure{
+				Message: "heartbeat timeout",
+				FailureInfo: &failurepb.Failure_TimeoutFailureInfo{TimeoutFailureInfo: &failurepb.TimeoutFailureInfo{
+					TimeoutType:          enumspb.TIMEOUT_TYPE_HEARTBEAT,
+					LastHeartbeatDetails: payloads.EncodeInt(42),
+				}},
+			},
+		},
+	}
+}
+
+func TestFailureLevels(t *testing.T) {
+	require.Equal(t, []failureLevel{
+		{
+			Type:    "ActivityFailure (activity Charge 5, retry state NonRetryableFailure)",
+			Message: "activity error",
+		},
+		{
+			Type:       "ApplicationFailure (type CardDeclined, non-retryable)",
+			Message:    "card declined",
+			Source:     "GoSDK",
+			Details:    "insufficient funds",
+			StackTrace: "main.Charge()\n\t/app/activities.go:42",
+		},
+		{
+			Type:    "TimeoutFailure (Heartbeat)",
+			Message: "heartbeat timeout",
+			Details: "42",
+		},
+	}, failureLevels(activityFailure()))
+}
+
+func TestFormatFailure(t *testing.T) {
+	require.Equal(t, "  ActivityFailure (activity Charge 5, retry state NonRetryableFailure): activity error\n"+
+		"  Caused by ApplicationFailure (type CardDeclined, non-retryable): card declined\n"+
+		"    Source: GoSDK\n"+
+		"    Details: insufficient funds\n"+
+		"    Stack trace:\n"+
+		"      main.Charge()\n"+
+		"      \t/app/activities.go:42\n"+
+		"  Caused by TimeoutFailure (Heartbeat): heartbeat timeout\n"+
+		"    Details: 42", formatFailure(nil, activityFailure(), "  "))
+}
+
+func TestConvertFailure(t *testing.T) {
+	f := convertFailure(activityFailure())
+	require.Equal(t, "Failure_ActivityFailureInfo", f.GetFailureType())
+	require.Equal(t, "card declined", f.GetCause().GetMessage())
+	require.Equal(t, "Failure_TimeoutFailureInfo: Heartbeat", f.GetCause().GetCause().GetFailureType())
+
+	// failures without failure info are still converted
+	require.Equal(t, "", convertFailure(&failurepb.Failure{Message: "boom"}).GetFailureType())
+}
+
+func TestHistoryEventToString_Failure(t *testing.T) {
+	event := &historypb.HistoryEvent{
+		EventType: enumspb.EVENT_TYPE_ACTIVITY_TASK_FAILED,
+		Attributes: &historypb.HistoryEvent_ActivityTaskFailedEventAttributes{ActivityTaskFailedEventAttributes: &historypb.ActivityTaskFailedEventAttributes{
+			Failure:          activityFailure().GetCause(),
+			ScheduledEventId: 5,
+		}},
+	}
+
+	s := HistoryEventToString(nil, event, true, 0)
+	require.Contains(t, s, "ScheduledEventId:5")
+	require.Contains(t, s, "  ApplicationFailure (type CardDeclined, non-retryable): card declined\n")
+	require.Contains(t, s, "  Caused by TimeoutFailure (Heartbeat): heartbeat timeout\n    Details: 42")
+	// the failure is not printed field by field
+	require.NotContains(t, s, "Message:")
+	// the event itself is left untouched
+	require.NotNil(t, event.GetActivityTaskFailedEventAttributes().GetFailure())
+}
+
+func (s *cliAppSuite) TestDescribeWorkflow_PendingActivityFailure() {
+	s.frontendClient.EXPECT().DescribeWorkflowExecution(gomock.Any(), gomock.Any()).Return(&workflowservice.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: &workflowpb.WorkflowExecutionInfo{
+			Execution: &commonpb.WorkflowExecution{WorkflowId: "wid", RunId: "rid"},
+			Status:    enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING,
+		},
+		PendingActivities: []*workflowpb.PendingActivityInfo{{
+			ActivityId:  "5",
+			LastFailure: activityFailure(),
+		}},
+	}, nil)
+
+	out := captureStdout(s.T(), func() {
+		err := s.app.Run([]string{"", "--namespace", cliTestNamespace, "workflow", "describe", "--workflow-id", "wid"})
+		s.NoError(err)
+	})
+
+	var description struct {
+		WorkflowExecutionInfo struct {
+			Execution struct {
+				WorkflowID string `json:"workflowId"`
+			} `json:"execution"`
+		} `json:"workflowExecutionInfo"`
+		PendingActivities []struct {
+			LastFailure struct {
+				Message     string `json:"message"`
+				FailureType string `json:"failureType"`
+			} `json:"lastFailure"`
+		} `json:"pendingActivities"`
+		PendingActivityFailures []pendingActivityFailure `json:"pendingActivityFailures"`
+	}
+	s.NoError(json.Unmarshal([]byte(out), &description), out)
+	s.Equal("wid", description.WorkflowExecutionInfo.Execution.WorkflowID)
+	// the converted failure is unchanged
+	s.Equal("activity error", description.PendingActivities[0].LastFailure.Message)
+	s.Equal("Failure_ActivityFailureInfo", description.PendingActivities[0].LastFailure.FailureType)
+	s.Equal([]pendingActivityFailure{{ActivityID: "5", LastFailure: failureLevels(activityFailure())}}, description.PendingActivityFailures)
+	s.Equal("insufficient funds", description.PendingActivityFailures[0].LastFailure[1].Details)
+}
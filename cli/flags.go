@@ -28,6 +28,8 @@ import (
 	"fmt"
 
 	"github.com/urfave/cli/v2"
+
+	"github.com/temporalio/tctl-kit/pkg/output"
 )
 
 // Flags used to specify cli command line arguments
@@ -60,6 +62,18 @@ var (
 	FlagTaskQueueAlias                = []string{"tq"}
 	FlagTaskQueueType                 = "task-queue-type"
 	FlagTaskQueueTypeAlias            = []string{"tqt"}
+	FlagStaleAfter                    = "stale-after"
+	FlagWaitTimeout                   = "timeout"
+	FlagFollowRuns                    = "follow-runs"
+	FlagDepth                         = "depth"
+	FlagTreeFormat                    = "format"
+	FlagWidth                         = "width"
+	FlagOut                           = "out"
+	FlagMaxWorkflowTaskAttempts       = "max-workflow-task-attempts"
+	FlagMaxActivityAttempts           = "max-activity-attempts"
+	FlagNoProgress                    = "no-progress"
+	FlagHistoryLength                 = "history-length"
+	FlagAtEvent                       = "at-event"
 	FlagWorkflowIDReusePolicy         = "workflow-id-reuse-policy"
 	FlagCronSchedule                  = "cron"
 	FlagWorkflowType                  = "type"
@@ -138,7 +152,7 @@ var (
 	FlagQueryType                     = "query-type"
 	FlagQueryTypeAlias                = []string{"qt"}
 	FlagQueryRejectCondition          = "query-reject-condition"
-	FlagQueryRejectConditionAlias     = []string{"qrc"}
+	FlagQueryRejectConditionAlias     = []string{"qrc", "reject-condition"}
 	FlagShowDetail                    = "show-detail"
 	FlagShowDetailAlias               = []string{"sd"}
 	FlagActiveClusterName             = "active-cluster"
@@ -221,10 +235,21 @@ var (
 	FlagAutoConfirm                   = "auto-confirm"
 	FlagDataConverterPlugin           = "data-converter-plugin"
 	FlagCodecAuth                     = "codec-auth"
+	FlagGRPCMeta                      = "grpc-meta"
+	FlagEnv                           = "env"
+	FlagIncludeSecrets                = "include-secrets"
+	FlagOverwrite                     = "overwrite"
 	FlagCodecEndpoint                 = "codec-endpoint"
 	FlagWebURL                        = "web-ui-url"
 	FlagHeadersProviderPlugin         = "headers-provider-plugin"
 	FlagHeadersProviderPluginOptions  = "headers-provider-plugin-options"
+	FlagOAuthFlow                     = "oauth-flow"
+	FlagOAuthClientID                 = "oauth-client-id"
+	FlagOAuthClientSecret             = "oauth-client-secret"
+	FlagOAuthTokenURL                 = "oauth-token-url"
+	FlagOAuthDeviceAuthURL            = "oauth-device-auth-url"
+	FlagOAuthScopes                   = "oauth-scopes"
+	FlagOAuthAudience                 = "oauth-audience"
 	FlagVersion                       = "version"
 	FlagPort                          = "port"
 	FlagEnableConnection              = "enable-connection"
@@ -252,6 +277,11 @@ var flagsForExecution = []cli.Flag{
 	},
 }
 
+var flagFollowRuns = &cli.BoolFlag{
+	Name:  FlagFollowRuns,
+	Usage: "Follow the runs that continue the workflow after a continue-as-new, a retry or for the next cron schedule",
+}
+
 var flagsForShowWorkflow = []cli.Flag{
 	&cli.StringFlag{
 		Name:    FlagOutputFilename,
@@ -273,6 +303,7 @@ var flagsForShowWorkflow = []cli.Flag{
 		Usage: "Follow the progress of workflow execution",
 		Value: false,
 	},
+	flagFollowRuns,
 }
 
 var flagsForRunWorkflow = []cli.Flag{
@@ -365,6 +396,7 @@ var flagsForRunWorkflow = []cli.Flag{
 		Usage: fmt.Sprintf("Optional search attributes value that can be be used in list query in JSON format. If there are multiple values, provide multiple %s flags. "+
 			"If value is array, use JSON array syntax: [\"a\",\"b\"] or [1,2].", FlagSearchAttributeValue),
 	},
+	flagFollowRuns,
 }
 
 var flagsForWorkflowFiltering = []cli.Flag{
@@ -429,7 +461,37 @@ func getFlagsForCount() []cli.Flag {
 	}
 }
 
-var flagsForStackTraceQuery = append(flagsForExecution, []cli.Flag{
+var flagsForStackTraceQuery = append(flagsForExecution, flagsForQuery...)
+
+var flagsForStack = append([]cli.Flag{
+	&cli.StringFlag{
+		Name:    FlagWorkflowID,
+		Aliases: FlagWorkflowIDAlias,
+		Usage:   "Workflow ID. Either this or --query is required",
+	},
+	&cli.StringFlag{
+		Name:    FlagRunID,
+		Aliases: FlagRunIDAlias,
+		Usage:   "Run Id",
+	},
+	&cli.StringFlag{
+		Name:    FlagListQuery,
+		Aliases: FlagListQueryAlias,
+		Usage:   "Visibility query of the workflows to get the stack traces of, for example \"ExecutionStatus='Running'\". Identical stack traces are grouped",
+	},
+	&cli.IntFlag{
+		Name:  FlagConcurrency,
+		Value: defaultStackTraceConcurrency,
+		Usage: "Number of workflows to query in parallel, used with --query",
+	},
+	&cli.IntFlag{
+		Name:  output.FlagLimit,
+		Value: defaultStackTraceLimit,
+		Usage: "Maximum number of workflows to query, used with --query",
+	},
+}, flagsForQuery...)
+
+var flagsForQuery = []cli.Flag{
 	&cli.StringFlag{
 		Name:    FlagInput,
 		Aliases: FlagInputAlias,
@@ -446,4 +508,10 @@ var flagsForStackTraceQuery = append(flagsForExecution, []cli.Flag{
 		Aliases: FlagQueryRejectConditionAlias,
 		Usage:   "Optional flag to reject queries based on workflow state. Valid values are \"not_open\" and \"not_completed_cleanly\"",
 	},
-}...)
+	&cli.StringFlag{
+		Name:    output.FlagOutput,
+		Aliases: []string{"o"},
+		Usage:   output.UsageText,
+		Value:   string(output.Table),
+	},
+}
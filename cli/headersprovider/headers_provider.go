@@ -24,6 +24,8 @@ package headersprovider
 
 import (
 	"context"
+	"strings"
+	"sync"
 
 	"github.com/temporalio/tctl/cli/plugin"
 )
@@ -32,8 +34,15 @@ type HeadersProvider interface {
 	GetHeaders(context.Context) (map[string]string, error)
 }
 
+// Invalidator is implemented by headers providers that cache credentials.
+// Invalidate is called when the server rejects the credentials, so that the next GetHeaders call obtains new ones.
+type Invalidator interface {
+	Invalidate()
+}
+
 var (
 	headersProvider plugin.HeadersProvider = nil
+	staticHeaders   map[string]string
 )
 
 type authHeaderProvider struct {
@@ -47,13 +56,118 @@ func (a authHeaderProvider) GetHeaders(ctx context.Context) (map[string]string,
 }
 
 func SetAuthorizationHeader(value string) {
-	headersProvider = &authHeaderProvider{value: value}
+	headersProvider = NewAuthorizationHeaderProvider(value)
+}
+
+// NewAuthorizationHeaderProvider creates a headers provider that sets the Authorization header to a static value
+func NewAuthorizationHeaderProvider(value string) HeadersProvider {
+	return &authHeaderProvider{value: value}
 }
 
 func SetCurrent(hp plugin.HeadersProvider) {
 	headersProvider = hp
 }
 
+// SetStaticHeaders sets headers that are sent with every request in addition to the headers of the current provider
+func SetStaticHeaders(headers map[string]string) {
+	staticHeaders = headers
+}
+
+// GetCurrent returns the current headers provider merged with the static headers,
+// or nil if there are no headers to set
 func GetCurrent() plugin.HeadersProvider {
-	return headersProvider
+	if len(staticHeaders) == 0 {
+		return headersProvider
+	}
+
+	return &mergedHeadersProvider{static: staticHeaders, provider: headersProvider}
+}
+
+// mergedHeadersProvider adds static headers to the output of a headers provider.
+// Headers returned by the provider take precedence, so that static headers can't override credentials.
+type mergedHeadersProvider struct {
+	static   map[string]string
+	provider plugin.HeadersProvider
+}
+
+func (m *mergedHeadersProvider) GetHeaders(ctx context.Context) (map[string]string, error) {
+	headers := make(map[string]string, len(m.static))
+	for k, v := range m.static {
+		headers[strings.ToLower(k)] = v
+	}
+
+	if m.provider == nil {
+		return headers, nil
+	}
+
+	provided, err := m.provider.GetHeaders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range provided {
+		// gRPC metadata keys are case insensitive
+		headers[strings.ToLower(k)] = v
+	}
+
+	return headers, nil
+}
+
+func (m *mergedHeadersProvider) Invalidate() {
+	Invalidate(m.provider)
+}
+
+// Invalidate drops cached credentials of the headers provider, if it caches any
+func Invalidate(hp plugin.HeadersProvider) {
+	if invalidator, ok := hp.(Invalidator); ok {
+		invalidator.Invalidate()
+	}
+}
+
+// lazyHeadersProvider creates the underlying provider on first use, so that credentials are only
+// obtained by commands that actually make requests
+type lazyHeadersProvider struct {
+	create func() (HeadersProvider, error)
+
+	lock     sync.Mutex
+	provider HeadersProvider
+}
+
+// NewLazyProvider returns a headers provider that calls create on first use.
+// Failed attempts are retried on the next use.
+func NewLazyProvider(create func() (HeadersProvider, error)) HeadersProvider {
+	return &lazyHeadersProvider{create: create}
+}
+
+func (l *lazyHeadersProvider) GetHeaders(ctx context.Context) (map[string]string, error) {
+	provider, err := l.get()
+	if err != nil {
+		return nil, err
+	}
+
+	return provider.GetHeaders(ctx)
+}
+
+func (l *lazyHeadersProvider) Invalidate() {
+	l.lock.Lock()
+	provider := l.provider
+	l.lock.Unlock()
+
+	if provider != nil {
+		Invalidate(provider)
+	}
+}
+
+func (l *lazyHeadersProvider) get() (HeadersProvider, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.provider == nil {
+		provider, err := l.create()
+		if err != nil {
+			return nil, err
+		}
+		l.provider = provider
+	}
+
+	return l.provider, nil
 }
@@ -0,0 +1,54 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package headersprovider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCurrent_MergesStaticHeaders(t *testing.T) {
+	defer func() {
+		SetCurrent(nil)
+		SetStaticHeaders(nil)
+	}()
+
+	SetAuthorizationHeader("Bearer token")
+	SetStaticHeaders(map[string]string{"tenant-id": "acme", "authorization": "static"})
+
+	headers, err := GetCurrent().GetHeaders(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"tenant-id": "acme", "authorization": "Bearer token"}, headers)
+}
+
+func TestGetCurrent_StaticHeadersOnly(t *testing.T) {
+	defer SetStaticHeaders(nil)
+
+	SetStaticHeaders(map[string]string{"tenant-id": "acme"})
+
+	headers, err := GetCurrent().GetHeaders(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"tenant-id": "acme"}, headers)
+}
@@ -0,0 +1,260 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package headersprovider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const (
+	OAuthFlowClientCredentials = "client-credentials"
+	OAuthFlowDeviceCode        = "device-code"
+
+	// tokens are refreshed this long before they expire, so that a token never expires mid-request
+	oauthRefreshBeforeExpiry = time.Minute
+)
+
+// OAuthConfig describes how to obtain access tokens from an OAuth2/OIDC provider
+type OAuthConfig struct {
+	Flow          string
+	ClientID      string
+	ClientSecret  string
+	TokenURL      string
+	DeviceAuthURL string
+	Scopes        []string
+	Audience      string
+	// CacheDir is the directory where tokens are cached between invocations. Caching is disabled if empty.
+	CacheDir string
+	// NonInteractive prevents starting the device code flow, which waits for the user to log in.
+	// Only cached tokens, refreshed if needed, are used then.
+	NonInteractive bool
+}
+
+// ErrLoginRequired is returned in non-interactive mode when the user has to log in to get a token
+var ErrLoginRequired = errors.New("no cached token, run a tctl command to log in")
+
+type oauthProvider struct {
+	config    OAuthConfig
+	cachePath string
+
+	lock  sync.Mutex
+	token *oauth2.Token
+}
+
+// NewOAuthProvider creates a headers provider that sets the Authorization header
+// to an access token obtained using the OAuth2 client credentials or device code flow
+func NewOAuthProvider(config OAuthConfig) (HeadersProvider, error) {
+	if config.ClientID == "" {
+		return nil, errors.New("client id is required")
+	}
+	if config.TokenURL == "" {
+		return nil, errors.New("token URL is required")
+	}
+
+	switch config.Flow {
+	case OAuthFlowClientCredentials:
+		if config.ClientSecret == "" {
+			return nil, fmt.Errorf("client secret is required for %s flow", config.Flow)
+		}
+	case OAuthFlowDeviceCode:
+		if config.DeviceAuthURL == "" {
+			return nil, fmt.Errorf("device authorization URL is required for %s flow", config.Flow)
+		}
+	default:
+		return nil, fmt.Errorf("unknown OAuth flow %q, valid values are %q and %q", config.Flow, OAuthFlowClientCredentials, OAuthFlowDeviceCode)
+	}
+
+	p := &oauthProvider{config: config}
+	if config.CacheDir != "" {
+		p.cachePath = filepath.Join(config.CacheDir, "oauth", cacheKey(config)+".json")
+	}
+
+	return p, nil
+}
+
+func (p *oauthProvider) GetHeaders(ctx context.Context) (map[string]string, error) {
+	token, err := p.getToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get OAuth token: %w", err)
+	}
+
+	return map[string]string{
+		"Authorization": token.Type() + " " + token.AccessToken,
+	}, nil
+}
+
+// Invalidate drops the access token, so that a new one is requested. The refresh token is kept.
+func (p *oauthProvider) Invalidate() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.token != nil {
+		p.token = &oauth2.Token{RefreshToken: p.token.RefreshToken}
+	}
+}
+
+func (p *oauthProvider) getToken(ctx context.Context) (*oauth2.Token, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.token == nil {
+		p.token = p.readCachedToken()
+	}
+	if isFresh(p.token) {
+		return p.token, nil
+	}
+
+	var token *oauth2.Token
+	if p.token != nil && p.token.RefreshToken != "" {
+		// fall back to the full flow if the refresh token was revoked or has expired
+		token, _ = p.refreshToken(ctx, p.token.RefreshToken)
+	}
+	if token == nil {
+		var err error
+		if token, err = p.newToken(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if token.RefreshToken == "" && p.token != nil {
+		// providers are not required to rotate refresh tokens
+		token.RefreshToken = p.token.RefreshToken
+	}
+
+	p.token = token
+	p.writeCachedToken(token)
+
+	return token, nil
+}
+
+func (p *oauthProvider) newToken(ctx context.Context) (*oauth2.Token, error) {
+	switch p.config.Flow {
+	case OAuthFlowClientCredentials:
+		cc := &clientcredentials.Config{
+			ClientID:       p.config.ClientID,
+			ClientSecret:   p.config.ClientSecret,
+			TokenURL:       p.config.TokenURL,
+			Scopes:         p.config.Scopes,
+			EndpointParams: p.endpointParams(),
+		}
+		return cc.Token(ctx)
+	case OAuthFlowDeviceCode:
+		if p.config.NonInteractive {
+			return nil, ErrLoginRequired
+		}
+		// the user may take a while to approve the request, so it shouldn't be bound to the deadline of an RPC
+		return deviceCodeToken(context.Background(), p.config, os.Stderr)
+	default:
+		return nil, fmt.Errorf("unknown OAuth flow %q", p.config.Flow)
+	}
+}
+
+func (p *oauthProvider) refreshToken(ctx context.Context, refreshToken string) (*oauth2.Token, error) {
+	conf := &oauth2.Config{
+		ClientID:     p.config.ClientID,
+		ClientSecret: p.config.ClientSecret,
+		Endpoint:     oauth2.Endpoint{TokenURL: p.config.TokenURL},
+		Scopes:       p.config.Scopes,
+	}
+
+	// a token without an access token is always refreshed by the token source
+	return conf.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+}
+
+func (p *oauthProvider) endpointParams() url.Values {
+	if p.config.Audience == "" {
+		return nil
+	}
+
+	return url.Values{"audience": {p.config.Audience}}
+}
+
+func (p *oauthProvider) readCachedToken() *oauth2.Token {
+	if p.cachePath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(p.cachePath)
+	if err != nil {
+		return nil
+	}
+
+	var token oauth2.Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil
+	}
+
+	return &token
+}
+
+func (p *oauthProvider) writeCachedToken(token *oauth2.Token) {
+	if p.cachePath == "" {
+		return
+	}
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		return
+	}
+
+	// failing to cache the token only means that it will be requested again next time
+	if err := os.MkdirAll(filepath.Dir(p.cachePath), 0700); err != nil {
+		return
+	}
+	_ = os.WriteFile(p.cachePath, data, 0600)
+}
+
+func isFresh(token *oauth2.Token) bool {
+	if token == nil || token.AccessToken == "" {
+		return false
+	}
+	if token.Expiry.IsZero() {
+		return true
+	}
+
+	return time.Until(token.Expiry) > oauthRefreshBeforeExpiry
+}
+
+// cacheKey identifies tokens issued for the same client, provider and scopes
+func cacheKey(config OAuthConfig) string {
+	h := sha256.New()
+	for _, part := range []string{config.Flow, config.TokenURL, config.ClientID, config.Audience, strings.Join(config.Scopes, " ")} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
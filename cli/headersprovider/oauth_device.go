@@ -0,0 +1,184 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package headersprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	deviceCodeGrantType       = "urn:ietf:params:oauth:grant-type:device_code"
+	defaultDeviceCodeInterval = 5 * time.Second
+	defaultDeviceCodeExpiry   = 5 * time.Minute
+)
+
+var deviceCodeHTTPClient = &http.Client{
+	Timeout: 30 * time.Second,
+}
+
+// deviceAuthResponse is the device authorization response defined in RFC 8628, section 3.2
+type deviceAuthResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+	Error                   string `json:"error"`
+	ErrorDescription        string `json:"error_description"`
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// deviceCodeToken obtains a token using the OAuth2 device authorization grant (RFC 8628).
+// Instructions for the user are written to out.
+func deviceCodeToken(ctx context.Context, config OAuthConfig, out io.Writer) (*oauth2.Token, error) {
+	form := url.Values{"client_id": {config.ClientID}}
+	if len(config.Scopes) > 0 {
+		form.Set("scope", strings.Join(config.Scopes, " "))
+	}
+	if config.Audience != "" {
+		form.Set("audience", config.Audience)
+	}
+
+	var auth deviceAuthResponse
+	if err := postForm(ctx, config.DeviceAuthURL, form, &auth); err != nil {
+		return nil, fmt.Errorf("device authorization request failed: %w", err)
+	}
+	if auth.Error != "" {
+		return nil, fmt.Errorf("device authorization request failed: %s %s", auth.Error, auth.ErrorDescription)
+	}
+	if auth.DeviceCode == "" {
+		return nil, fmt.Errorf("device authorization response has no device code")
+	}
+
+	if auth.VerificationURIComplete != "" {
+		fmt.Fprintf(out, "To authenticate, open %s in a browser and confirm the code %s\n", auth.VerificationURIComplete, auth.UserCode)
+	} else {
+		fmt.Fprintf(out, "To authenticate, open %s in a browser and enter the code %s\n", auth.VerificationURI, auth.UserCode)
+	}
+
+	interval := defaultDeviceCodeInterval
+	if auth.Interval > 0 {
+		interval = time.Duration(auth.Interval) * time.Second
+	}
+	expiry := defaultDeviceCodeExpiry
+	if auth.ExpiresIn > 0 {
+		expiry = time.Duration(auth.ExpiresIn) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, expiry)
+	defer cancel()
+
+	form = url.Values{
+		"grant_type":  {deviceCodeGrantType},
+		"device_code": {auth.DeviceCode},
+		"client_id":   {config.ClientID},
+	}
+	if config.ClientSecret != "" {
+		form.Set("client_secret", config.ClientSecret)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("device code was not confirmed in time")
+		case <-time.After(interval):
+		}
+
+		var resp tokenResponse
+		if err := postForm(ctx, config.TokenURL, form, &resp); err != nil {
+			return nil, fmt.Errorf("token request failed: %w", err)
+		}
+
+		switch resp.Error {
+		case "":
+			return resp.toToken(), nil
+		case "authorization_pending":
+			continue
+		case "slow_down":
+			interval += 5 * time.Second
+			continue
+		default:
+			if resp.ErrorDescription != "" {
+				return nil, fmt.Errorf("%s: %s", resp.Error, resp.ErrorDescription)
+			}
+			return nil, fmt.Errorf("%s", resp.Error)
+		}
+	}
+}
+
+func (r *tokenResponse) toToken() *oauth2.Token {
+	token := &oauth2.Token{
+		AccessToken:  r.AccessToken,
+		TokenType:    r.TokenType,
+		RefreshToken: r.RefreshToken,
+	}
+	if r.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(r.ExpiresIn) * time.Second)
+	}
+
+	return token
+}
+
+// postForm posts the form and decodes the JSON response. Error responses of the token endpoint
+// (RFC 6749, section 5.2) are decoded as well, so that the caller can inspect the error code.
+func postForm(ctx context.Context, endpoint string, form url.Values, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := deviceCodeHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("unexpected response (status %d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
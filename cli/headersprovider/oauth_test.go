@@ -0,0 +1,157 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package headersprovider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/oauth2"
+)
+
+type (
+	OAuthSuite struct {
+		*require.Assertions
+		suite.Suite
+
+		server   *httptest.Server
+		requests int
+		// expiresIn is returned with every issued token
+		expiresIn int64
+	}
+)
+
+func TestOAuthSuite(t *testing.T) {
+	suite.Run(t, &OAuthSuite{})
+}
+
+func (s *OAuthSuite) SetupTest() {
+	s.Assertions = require.New(s.T())
+	s.requests = 0
+	s.expiresIn = 3600
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests++
+		s.NoError(r.ParseForm())
+		if r.Form.Get("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "token-" + r.Form.Get("audience"),
+			"token_type":   "Bearer",
+			"expires_in":   s.expiresIn,
+		})
+	}))
+}
+
+func (s *OAuthSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *OAuthSuite) newProvider(cacheDir string) HeadersProvider {
+	p, err := NewOAuthProvider(OAuthConfig{
+		Flow:         OAuthFlowClientCredentials,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		TokenURL:     s.server.URL,
+		Audience:     "temporal",
+		CacheDir:     cacheDir,
+	})
+	s.NoError(err)
+	return p
+}
+
+func (s *OAuthSuite) TestClientCredentials_ReusesToken() {
+	p := s.newProvider("")
+
+	for i := 0; i < 2; i++ {
+		headers, err := p.GetHeaders(context.Background())
+		s.NoError(err)
+		s.Equal("Bearer token-temporal", headers["Authorization"])
+	}
+	s.Equal(1, s.requests)
+}
+
+func (s *OAuthSuite) TestClientCredentials_RefreshesBeforeExpiry() {
+	s.expiresIn = 30
+	p := s.newProvider("")
+
+	_, err := p.GetHeaders(context.Background())
+	s.NoError(err)
+	_, err = p.GetHeaders(context.Background())
+	s.NoError(err)
+	s.Equal(2, s.requests)
+}
+
+func (s *OAuthSuite) TestClientCredentials_CachesToken() {
+	cacheDir := s.T().TempDir()
+
+	_, err := s.newProvider(cacheDir).GetHeaders(context.Background())
+	s.NoError(err)
+	headers, err := s.newProvider(cacheDir).GetHeaders(context.Background())
+	s.NoError(err)
+	s.Equal("Bearer token-temporal", headers["Authorization"])
+	s.Equal(1, s.requests)
+}
+
+func (s *OAuthSuite) TestNewOAuthProvider_Validation() {
+	_, err := NewOAuthProvider(OAuthConfig{Flow: OAuthFlowClientCredentials, ClientID: "client", TokenURL: s.server.URL})
+	s.Error(err)
+	_, err = NewOAuthProvider(OAuthConfig{Flow: OAuthFlowDeviceCode, ClientID: "client", TokenURL: s.server.URL})
+	s.Error(err)
+	_, err = NewOAuthProvider(OAuthConfig{Flow: "password", ClientID: "client", TokenURL: s.server.URL})
+	s.Error(err)
+}
+
+func (s *OAuthSuite) TestDeviceCode_NonInteractive() {
+	cacheDir := s.T().TempDir()
+	config := OAuthConfig{
+		Flow:           OAuthFlowDeviceCode,
+		ClientID:       "client",
+		TokenURL:       s.server.URL,
+		DeviceAuthURL:  s.server.URL,
+		CacheDir:       cacheDir,
+		NonInteractive: true,
+	}
+	p, err := NewOAuthProvider(config)
+	s.NoError(err)
+
+	_, err = p.GetHeaders(context.Background())
+	s.ErrorIs(err, ErrLoginRequired)
+	s.Equal(0, s.requests)
+
+	// a token cached by an interactive invocation is used
+	cached, err := NewOAuthProvider(config)
+	s.NoError(err)
+	cached.(*oauthProvider).writeCachedToken(&oauth2.Token{AccessToken: "cached", TokenType: "Bearer"})
+	headers, err := cached.GetHeaders(context.Background())
+	s.NoError(err)
+	s.Equal("Bearer cached", headers["Authorization"])
+}
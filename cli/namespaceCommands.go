@@ -48,7 +48,10 @@ func RegisterNamespace(c *cli.Context) error {
 	description := c.String(FlagDescription)
 	ownerEmail := c.String(FlagOwnerEmail)
 
-	client := cFactory.FrontendClient(c)
+	client, err := cFactory.FrontendClient(c)
+	if err != nil {
+		return err
+	}
 
 	retention := defaultNamespaceRetention
 	if c.IsSet(FlagRetention) {
@@ -146,7 +149,10 @@ func UpdateNamespace(c *cli.Context) error {
 		return err
 	}
 
-	client := cFactory.FrontendClient(c)
+	client, err := cFactory.FrontendClient(c)
+	if err != nil {
+		return err
+	}
 
 	var updateRequest *workflowservice.UpdateNamespaceRequest
 	ctx, cancel := newContext(c)
@@ -308,7 +314,10 @@ func DescribeNamespace(c *cli.Context) error {
 		namespace = ""
 	}
 
-	client := cFactory.FrontendClient(c)
+	client, err := cFactory.FrontendClient(c)
+	if err != nil {
+		return err
+	}
 
 	ctx, cancel := newContext(c)
 	defer cancel()
@@ -333,7 +342,10 @@ func DescribeNamespace(c *cli.Context) error {
 
 // ListNamespaces list all namespaces
 func ListNamespaces(c *cli.Context) error {
-	client := cFactory.FrontendClient(c)
+	client, err := cFactory.FrontendClient(c)
+	if err != nil {
+		return err
+	}
 
 	namespaces, err := getAllNamespaces(c, client)
 	if err != nil {
@@ -25,9 +25,47 @@
 package cli
 
 import (
+	"fmt"
 	"syscall"
 
 	"github.com/urfave/cli/v2"
+
+	"github.com/temporalio/tctl-kit/pkg/flags"
+)
+
+func newPluginCommands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "list",
+			Usage: "List plugins found in PATH",
+			Flags: flags.FlagsForRendering,
+			Action: func(c *cli.Context) error {
+				return ListPlugins(c)
+			},
+		},
+	}
+}
+
+// pluginDescription documents the contract between tctl and plugins
+var pluginDescription = fmt.Sprintf(`Plugins are executables named %[1]s<name> in PATH, run as 'tctl <name> [arguments...]'.
+
+When run with %[2]s, a plugin should print a JSON object like {"description": "...", "version": "..."}
+and exit. A plain text description is accepted as well.
+
+Plugins receive the arguments following the plugin name. Global options are resolved by tctl
+(including the config environment) and passed as environment variables:
+   %[3]s
+   %[4]s
+   %[5]s, %[6]s, %[7]s
+   %[8]s, %[9]s
+   %[10]s, %[11]s
+   %[12]s (value of the authorization header)
+   %[13]s (other gRPC headers, comma separated key=value pairs)`,
+	pluginPrefix, pluginInfoFlag,
+	envAddress, envNamespace,
+	envTLSCert, envTLSKey, envTLSCa, envTLSDisableHostVerification, envTLSServerName,
+	envCodecEndpoint, envCodecAuth,
+	envAuth, envGRPCMeta,
 )
 
 func executePlugin(ctx *cli.Context, binPath string, args []string, envs []string) error {
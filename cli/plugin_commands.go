@@ -0,0 +1,281 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/temporalio/tctl-kit/pkg/output"
+	"github.com/temporalio/tctl/cli/headersprovider"
+	"github.com/temporalio/tctl/cli/plugin"
+)
+
+const (
+	pluginPrefix        = "tctl-"
+	pluginInfoFlag      = "--tctl-plugin-info"
+	pluginInfoTimeout   = 3 * time.Second
+	pluginsHelpTemplate = `{{with index .Metadata "plugins"}}{{with .Names}}
+PLUGINS:{{range .}}
+   {{.}}{{end}}
+{{end}}{{end}}`
+	// go-plugin based headers provider and data converter plugins are not commands
+	providerPluginSuffix = "-plugin"
+
+	// environment variables passed to plugins, named after the corresponding global flags
+	envAddress                    = "TEMPORAL_CLI_ADDRESS"
+	envNamespace                  = "TEMPORAL_CLI_NAMESPACE"
+	envAuth                       = "TEMPORAL_CLI_AUTH"
+	envGRPCMeta                   = "TEMPORAL_CLI_GRPC_META"
+	envTLSCert                    = "TEMPORAL_CLI_TLS_CERT"
+	envTLSKey                     = "TEMPORAL_CLI_TLS_KEY"
+	envTLSCa                      = "TEMPORAL_CLI_TLS_CA"
+	envTLSDisableHostVerification = "TEMPORAL_CLI_TLS_DISABLE_HOST_VERIFICATION"
+	envTLSServerName              = "TEMPORAL_CLI_TLS_SERVER_NAME"
+	envCodecEndpoint              = "TEMPORAL_CLI_CODEC_ENDPOINT"
+	envCodecAuth                  = "TEMPORAL_CLI_CODEC_AUTH"
+)
+
+// pluginBinary is an executable found in PATH that can be run as a tctl command
+type pluginBinary struct {
+	Name string
+	Path string
+}
+
+// pluginInfo is printed by plugins when run with --tctl-plugin-info
+type pluginInfo struct {
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+// ListPlugins prints the plugins found in PATH with their descriptions
+func ListPlugins(c *cli.Context) error {
+	binaries := discoverPlugins()
+
+	type pluginItem struct {
+		Name        string
+		Version     string
+		Description string
+		Path        string
+	}
+	items := make([]interface{}, len(binaries))
+	var wg sync.WaitGroup
+	for i, bin := range binaries {
+		wg.Add(1)
+		go func(i int, bin pluginBinary) {
+			defer wg.Done()
+
+			info, err := queryPluginInfo(bin.Path)
+			if err != nil {
+				info = &pluginInfo{Description: fmt.Sprintf("(%s)", err)}
+			}
+			items[i] = pluginItem{Name: bin.Name, Version: info.Version, Description: info.Description, Path: bin.Path}
+		}(i, bin)
+	}
+	wg.Wait()
+
+	opts := &output.PrintOptions{
+		Fields:  []string{"Name", "Version", "Description", "Path"},
+		NoPager: true,
+	}
+	output.PrintItems(c, items, opts)
+
+	return nil
+}
+
+// discoverPlugins returns the plugins in PATH sorted by name. Like with exec.LookPath, the first one found for a name wins.
+func discoverPlugins() []pluginBinary {
+	found := make(map[string]pluginBinary)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			fileName := entry.Name()
+			if !strings.HasPrefix(fileName, pluginPrefix) || entry.IsDir() {
+				continue
+			}
+
+			name := strings.TrimPrefix(fileName, pluginPrefix)
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, ".exe")
+			}
+			if name == "" || strings.HasSuffix(name, providerPluginSuffix) {
+				continue
+			}
+			if _, ok := found[name]; ok {
+				continue
+			}
+
+			path := filepath.Join(dir, fileName)
+			if !isExecutable(path) {
+				continue
+			}
+			found[name] = pluginBinary{Name: name, Path: path}
+		}
+	}
+
+	binaries := make([]pluginBinary, 0, len(found))
+	for _, bin := range found {
+		binaries = append(binaries, bin)
+	}
+	sort.Slice(binaries, func(i, j int) bool {
+		return binaries[i].Name < binaries[j].Name
+	})
+
+	return binaries
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return strings.HasSuffix(path, ".exe")
+	}
+
+	return info.Mode()&0111 != 0
+}
+
+// queryPluginInfo runs the plugin with --tctl-plugin-info
+func queryPluginInfo(path string) (*pluginInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pluginInfoTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, path, pluginInfoFlag).Output()
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("no response to %s", pluginInfoFlag)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s is not supported", pluginInfoFlag)
+	}
+
+	var info pluginInfo
+	if err := json.Unmarshal(out, &info); err == nil {
+		return &info, nil
+	}
+
+	// plain text description
+	line, _ := bufio.NewReader(bytes.NewReader(out)).ReadString('\n')
+	return &pluginInfo{Description: strings.TrimSpace(line)}, nil
+}
+
+// runPlugin replaces the tctl process with the plugin
+func runPlugin(c *cli.Context, name string, path string, args []string) error {
+	env, err := pluginEnv(c)
+	if err != nil {
+		return err
+	}
+
+	// the process is replaced, so the After hook won't stop the plugins started by tctl
+	plugin.StopPlugins()
+
+	if err := executePlugin(c, path, append([]string{pluginPrefix + name}, args...), env); err != nil {
+		return fmt.Errorf("unable to run plugin %s: %s", path, err)
+	}
+	return nil
+}
+
+// pluginEnv returns the environment of tctl with the resolved connection settings, see pluginDescription
+func pluginEnv(c *cli.Context) ([]string, error) {
+	address := readFlagOrConfig(c, FlagAddress)
+	if address == "" {
+		address = localHostPort
+	}
+
+	vars := map[string]string{
+		envAddress:                    address,
+		envNamespace:                  readFlagOrConfig(c, FlagNamespace),
+		envTLSCert:                    readFlagOrConfig(c, FlagTLSCertPath),
+		envTLSKey:                     readFlagOrConfig(c, FlagTLSKeyPath),
+		envTLSCa:                      readFlagOrConfig(c, FlagTLSCaPath),
+		envTLSDisableHostVerification: readFlagOrConfig(c, FlagTLSDisableHostVerification),
+		envTLSServerName:              readFlagOrConfig(c, FlagTLSServerName),
+		envCodecEndpoint:              readFlagOrConfig(c, FlagCodecEndpoint),
+		envCodecAuth:                  readFlagOrConfig(c, FlagCodecAuth),
+	}
+
+	if hp := headersprovider.GetCurrent(); hp != nil {
+		headers, err := hp.GetHeaders(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("unable to get headers for plugin: %s", err)
+		}
+
+		var meta []string
+		for k, v := range headers {
+			if strings.EqualFold(k, "authorization") {
+				vars[envAuth] = v
+				continue
+			}
+			// the values are separated by commas, the same way as in TEMPORAL_CLI_GRPC_META read by tctl
+			if strings.Contains(v, ",") {
+				return nil, fmt.Errorf("unable to pass header %s to plugin: value can't contain commas", k)
+			}
+			meta = append(meta, strings.ToLower(k)+"="+v)
+		}
+		sort.Strings(meta)
+		vars[envGRPCMeta] = strings.Join(meta, ",")
+	}
+
+	var env []string
+	for _, kv := range os.Environ() {
+		name := strings.SplitN(kv, "=", 2)[0]
+		if _, ok := vars[name]; !ok {
+			env = append(env, kv)
+		}
+	}
+	for name, val := range vars {
+		if val != "" {
+			env = append(env, name+"="+val)
+		}
+	}
+
+	return env, nil
+}
+
+// pluginNames lists plugin names in the app help. PATH is only scanned when the help is printed.
+type pluginNames struct{}
+
+func (pluginNames) Names() []string {
+	var names []string
+	for _, bin := range discoverPlugins() {
+		names = append(names, bin.Name)
+	}
+	return names
+}
@@ -0,0 +1,63 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test plugins are shell scripts")
+	}
+
+	dir1, dir2 := t.TempDir(), t.TempDir()
+	writePlugin := func(dir, name, script string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0755))
+	}
+	writePlugin(dir1, "tctl-hello", `echo '{"description": "Says hello", "version": "1.0"}'`)
+	writePlugin(dir2, "tctl-hello", "echo shadowed")
+	writePlugin(dir2, "tctl-plain", "echo Plain description")
+	writePlugin(dir2, "tctl-authorization-plugin", "exit 1")
+	require.NoError(t, os.WriteFile(filepath.Join(dir2, "tctl-not-executable"), nil, 0644))
+	t.Setenv("PATH", dir1+string(os.PathListSeparator)+dir2)
+
+	plugins := discoverPlugins()
+	require.Equal(t, []pluginBinary{
+		{Name: "hello", Path: filepath.Join(dir1, "tctl-hello")},
+		{Name: "plain", Path: filepath.Join(dir2, "tctl-plain")},
+	}, plugins)
+
+	info, err := queryPluginInfo(plugins[0].Path)
+	require.NoError(t, err)
+	require.Equal(t, &pluginInfo{Description: "Says hello", Version: "1.0"}, info)
+
+	info, err = queryPluginInfo(plugins[1].Path)
+	require.NoError(t, err)
+	require.Equal(t, &pluginInfo{Description: "Plain description"}, info)
+}
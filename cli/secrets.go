@@ -0,0 +1,183 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	secretRefEnv  = "env:"
+	secretRefFile = "file:"
+	secretRefExec = "exec:"
+
+	secretExecTimeout = time.Minute
+	redactedValue     = "********"
+)
+
+var (
+	// secretKeys are the config keys whose plaintext values are redacted when printed,
+	// and the only keys whose secret references are resolved
+	secretKeys = []string{
+		FlagAuth,
+		FlagCodecAuth,
+		FlagOAuthClientSecret,
+	}
+
+	resolvedSecretsLock sync.Mutex
+	resolvedSecrets     = map[string]string{}
+)
+
+// isSecretReference returns whether the config value references a secret stored elsewhere:
+// env:VAR, file:/path or exec:command
+func isSecretReference(val string) bool {
+	return strings.HasPrefix(val, secretRefEnv) ||
+		strings.HasPrefix(val, secretRefFile) ||
+		strings.HasPrefix(val, secretRefExec)
+}
+
+// isSecretKey returns whether secret references in the value of the config key are resolved.
+// Other keys are used as is.
+func isSecretKey(key string) bool {
+	for _, k := range secretKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// checkConfigReference rejects exec: references outside of secret keys, so that a config value
+// such as an address can't run a command
+func checkConfigReference(key string, val string) error {
+	if strings.HasPrefix(val, secretRefExec) && !isSecretKey(key) {
+		return fmt.Errorf("%s references are only allowed in %s", secretRefExec, strings.Join(secretKeys, ", "))
+	}
+	return nil
+}
+
+// resolveSecret returns the secret the reference points to. Secrets are resolved once per invocation.
+func resolveSecret(ref string) (string, error) {
+	resolvedSecretsLock.Lock()
+	defer resolvedSecretsLock.Unlock()
+
+	if secret, ok := resolvedSecrets[ref]; ok {
+		return secret, nil
+	}
+
+	var secret string
+	var err error
+	switch {
+	case strings.HasPrefix(ref, secretRefEnv):
+		secret, err = resolveEnvSecret(strings.TrimPrefix(ref, secretRefEnv))
+	case strings.HasPrefix(ref, secretRefFile):
+		secret, err = resolveFileSecret(strings.TrimPrefix(ref, secretRefFile))
+	case strings.HasPrefix(ref, secretRefExec):
+		secret, err = resolveExecSecret(strings.TrimPrefix(ref, secretRefExec))
+	default:
+		return "", fmt.Errorf("not a secret reference: %q", ref)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	resolvedSecrets[ref] = secret
+	return secret, nil
+}
+
+func resolveEnvSecret(name string) (string, error) {
+	secret, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+
+	return secret, nil
+}
+
+func resolveFileSecret(path string) (string, error) {
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, path[2:])
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to read secret file: %w", err)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+// resolveExecSecret runs the command in a shell and returns its output.
+// Stdin and stderr are passed through, so that the command can prompt the user.
+func resolveExecSecret(command string) (string, error) {
+	if strings.TrimSpace(command) == "" {
+		return "", errors.New("command is empty")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), secretExecTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("command %q failed: %w", command, err)
+	}
+
+	secret := strings.TrimSpace(stdout.String())
+	if secret == "" {
+		return "", fmt.Errorf("command %q printed no secret", command)
+	}
+
+	return secret, nil
+}
+
+// redactConfigValue hides plaintext secrets stored in the config. Secret references are shown as is.
+func redactConfigValue(key string, val string) string {
+	if val == "" || isSecretReference(val) || !isSecretKey(key) {
+		return val
+	}
+	return redactedValue
+}
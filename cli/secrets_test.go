@@ -0,0 +1,99 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveSecret_Env(t *testing.T) {
+	t.Setenv("TCTL_TEST_SECRET", "env-token")
+
+	secret, err := resolveSecret("env:TCTL_TEST_SECRET")
+	require.NoError(t, err)
+	require.Equal(t, "env-token", secret)
+
+	_, err = resolveSecret("env:TCTL_TEST_SECRET_NOT_SET")
+	require.Error(t, err)
+}
+
+func TestResolveSecret_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte("file-token\n"), 0600))
+
+	secret, err := resolveSecret("file:" + path)
+	require.NoError(t, err)
+	require.Equal(t, "file-token", secret)
+
+	_, err = resolveSecret("file:" + path + ".missing")
+	require.Error(t, err)
+}
+
+func TestResolveSecret_Exec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test command requires a POSIX shell")
+	}
+
+	secret, err := resolveSecret("exec:echo exec-token")
+	require.NoError(t, err)
+	require.Equal(t, "exec-token", secret)
+
+	_, err = resolveSecret("exec:exit 1")
+	require.Error(t, err)
+}
+
+func TestRedactConfigValue(t *testing.T) {
+	require.Equal(t, redactedValue, redactConfigValue(FlagAuth, "Bearer token"))
+	require.Equal(t, "env:TOKEN", redactConfigValue(FlagAuth, "env:TOKEN"))
+	require.Equal(t, "", redactConfigValue(FlagCodecAuth, ""))
+	require.Equal(t, "localhost:7233", redactConfigValue(FlagAddress, "localhost:7233"))
+}
+
+func TestCheckConfigReference(t *testing.T) {
+	require.NoError(t, checkConfigReference(FlagAuth, "exec:vault read token"))
+	require.NoError(t, checkConfigReference(FlagAddress, "env:ADDRESS"))
+	require.NoError(t, checkConfigReference(FlagAddress, "localhost:7233"))
+	require.Error(t, checkConfigReference(FlagAddress, "exec:echo localhost:7233"))
+	require.Error(t, checkConfigReference(FlagGRPCMeta, "exec:echo tenant=acme"))
+}
+
+func TestValidateSecrets_ExecNotRun(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "marker")
+	t.Setenv("TCTL_TEST_SECRET", "env-token")
+
+	v := &configValidator{props: map[string]string{
+		FlagAuth:      "exec:touch " + marker,
+		FlagCodecAuth: "env:TCTL_TEST_SECRET",
+		FlagAddress:   "exec:touch " + marker,
+	}}
+	v.validateSecrets()
+
+	require.Equal(t, 1, v.problems)
+	_, err := os.Stat(marker)
+	require.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,221 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/urfave/cli/v2"
+	commonpb "go.temporal.io/api/common/v1"
+	workflowpb "go.temporal.io/api/workflow/v1"
+
+	"github.com/temporalio/tctl-kit/pkg/output"
+)
+
+// maxStackTraceSamples is the number of workflow Ids shown for each stack trace
+const maxStackTraceSamples = 5
+
+var (
+	// goroutine numbers and argument values differ between workflows blocked at the same point
+	goroutineNumberRegexp = regexp.MustCompile(`goroutine \d+`)
+	stackArgumentsRegexp  = regexp.MustCompile(`\((0x[0-9a-f]+|\.\.\.)(, (0x[0-9a-f]+|\.\.\.))*\)`)
+)
+
+// stackTraceGroup is a stack trace, or a query error, shared by several workflows
+type stackTraceGroup struct {
+	Count       int      `json:"count"`
+	WorkflowIDs []string `json:"workflowIds"`
+	StackTrace  string   `json:"stackTrace,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+type stackTraceSummary struct {
+	Workflows int                `json:"workflows"`
+	Groups    []*stackTraceGroup `json:"groups"`
+	Failures  []*stackTraceGroup `json:"failures,omitempty"`
+}
+
+// aggregateStackTraces queries the stack traces of the workflows matching the visibility query
+// and groups the workflows by stack trace, so that a common blocking point stands out
+func aggregateStackTraces(c *cli.Context) error {
+	sdkClient, err := getSDKClient(c)
+	if err != nil {
+		return err
+	}
+	namespace, err := getRequiredGlobalOption(c, FlagNamespace)
+	if err != nil {
+		return err
+	}
+	queryRequest, err := newQueryRequest(c, stackTraceQueryType)
+	if err != nil {
+		return err
+	}
+	concurrency := c.Int(FlagConcurrency)
+	if concurrency < 1 {
+		return fmt.Errorf("--%s must be at least 1", FlagConcurrency)
+	}
+	limit := c.Int(output.FlagLimit)
+	if limit < 1 {
+		return fmt.Errorf("--%s must be at least 1", output.FlagLimit)
+	}
+	query := c.String(FlagListQuery)
+
+	serviceClient, err := cFactory.FrontendClient(c)
+	if err != nil {
+		return err
+	}
+	executions := make(chan *commonpb.WorkflowExecution)
+	traces := map[string]*stackTraceGroup{}
+	failures := map[string]*stackTraceGroup{}
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for execution := range executions {
+				req := *queryRequest
+				req.Execution = execution
+
+				ctx, cancel := newContext(c)
+				resp, err := serviceClient.QueryWorkflow(ctx, &req)
+				cancel()
+
+				lock.Lock()
+				switch {
+				case err != nil:
+					addToStackTraceGroup(failures, execution, "", err.Error())
+				case resp.GetQueryRejected() != nil:
+					addToStackTraceGroup(failures, execution, "", fmt.Sprintf("query was rejected, workflow has status: %v", resp.GetQueryRejected().GetStatus()))
+				default:
+					addToStackTraceGroup(traces, execution, normalizeStackTrace(formatDecodedPayloads(decodePayloads(resp.GetQueryResult()))), "")
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+
+	var listErr error
+	count := 0
+	// set only if a workflow beyond the limit matches the query
+	limitReached := false
+	var nextPageToken []byte
+	for !limitReached {
+		var page []interface{}
+		page, nextPageToken, listErr = listWorkflows(c, sdkClient, nextPageToken, namespace, query)
+		if listErr != nil {
+			break
+		}
+		for _, item := range page {
+			if count >= limit {
+				limitReached = true
+				break
+			}
+			executions <- item.(*workflowpb.WorkflowExecutionInfo).GetExecution()
+			count++
+		}
+		if len(nextPageToken) == 0 {
+			break
+		}
+	}
+	close(executions)
+	wg.Wait()
+	if listErr != nil {
+		return listErr
+	}
+
+	summary := stackTraceSummary{
+		Workflows: count,
+		Groups:    sortStackTraceGroups(traces),
+		Failures:  sortStackTraceGroups(failures),
+	}
+	if c.String(output.FlagOutput) == string(output.JSON) {
+		output.PrintJSON(c, summary, &output.PrintOptions{Pager: os.Stdout})
+		return nil
+	}
+
+	printStackTraceSummary(summary, limitReached)
+	return nil
+}
+
+func addToStackTraceGroup(groups map[string]*stackTraceGroup, execution *commonpb.WorkflowExecution, stackTrace, errMessage string) {
+	key := stackTrace + errMessage
+	group, ok := groups[key]
+	if !ok {
+		group = &stackTraceGroup{StackTrace: stackTrace, Error: errMessage}
+		groups[key] = group
+	}
+
+	group.Count++
+	if len(group.WorkflowIDs) < maxStackTraceSamples {
+		group.WorkflowIDs = append(group.WorkflowIDs, execution.GetWorkflowId())
+	}
+}
+
+// sortStackTraceGroups orders the groups by the number of workflows, the most common first
+func sortStackTraceGroups(groups map[string]*stackTraceGroup) []*stackTraceGroup {
+	sorted := make([]*stackTraceGroup, 0, len(groups))
+	for _, group := range groups {
+		sort.Strings(group.WorkflowIDs)
+		sorted = append(sorted, group)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].StackTrace+sorted[i].Error < sorted[j].StackTrace+sorted[j].Error
+	})
+
+	return sorted
+}
+
+func normalizeStackTrace(stackTrace string) string {
+	stackTrace = goroutineNumberRegexp.ReplaceAllString(stackTrace, "goroutine N")
+	stackTrace = stackArgumentsRegexp.ReplaceAllString(stackTrace, "(...)")
+
+	return strings.TrimSpace(stackTrace)
+}
+
+func printStackTraceSummary(summary stackTraceSummary, limitReached bool) {
+	fmt.Printf("Queried %d workflows, found %d distinct stack traces", summary.Workflows, len(summary.Groups))
+	if limitReached {
+		fmt.Print(" (limit reached, more workflows may match the query)")
+	}
+	fmt.Println()
+
+	for _, group := range summary.Groups {
+		fmt.Printf("\n%d workflows, for example %s:\n%s\n", group.Count, strings.Join(group.WorkflowIDs, ", "), group.StackTrace)
+	}
+
+	if len(summary.Failures) > 0 {
+		fmt.Println("\nFailed to get the stack trace of:")
+		for _, group := range summary.Failures {
+			fmt.Printf("%d workflows, for example %s: %s\n", group.Count, strings.Join(group.WorkflowIDs, ", "), group.Error)
+		}
+	}
+}
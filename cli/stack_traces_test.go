@@ -0,0 +1,146 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/api/serviceerror"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/server/common/payloads"
+)
+
+func TestNormalizeStackTrace(t *testing.T) {
+	trace := "goroutine 42 [blocked on chan-1.Receive]:\n" +
+		"main.Workflow(0x1a2b3c, 0xc000123456, ...)\n" +
+		"\t/app/workflow.go:25 +0x1d\n"
+
+	require.Equal(t, "goroutine N [blocked on chan-1.Receive]:\n"+
+		"main.Workflow(...)\n"+
+		"\t/app/workflow.go:25 +0x1d", normalizeStackTrace(trace))
+}
+
+func TestSortStackTraceGroups(t *testing.T) {
+	groups := map[string]*stackTraceGroup{}
+	for i, wid := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		trace := "common"
+		if i == 0 {
+			trace = "rare"
+		}
+		addToStackTraceGroup(groups, &commonpb.WorkflowExecution{WorkflowId: wid}, trace, "")
+	}
+
+	sorted := sortStackTraceGroups(groups)
+	require.Len(t, sorted, 2)
+	require.Equal(t, &stackTraceGroup{Count: 6, WorkflowIDs: []string{"b", "c", "d", "e", "f"}, StackTrace: "common"}, sorted[0])
+	require.Equal(t, &stackTraceGroup{Count: 1, WorkflowIDs: []string{"a"}, StackTrace: "rare"}, sorted[1])
+}
+
+func (s *cliAppSuite) TestQueryWorkflowUsingStackTrace_Query() {
+	var executions []*workflowpb.WorkflowExecutionInfo
+	for _, wid := range []string{"wid-1", "wid-2", "wid-3"} {
+		executions = append(executions, &workflowpb.WorkflowExecutionInfo{
+			Execution: &commonpb.WorkflowExecution{WorkflowId: wid, RunId: "rid"},
+		})
+	}
+	s.sdkClient.On("ListWorkflow", mock.Anything, mock.Anything).Return(&workflowservice.ListWorkflowExecutionsResponse{
+		Executions: executions,
+	}, nil).Once()
+	s.frontendClient.EXPECT().QueryWorkflow(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *workflowservice.QueryWorkflowRequest, _ ...interface{}) (*workflowservice.QueryWorkflowResponse, error) {
+			s.Equal(stackTraceQueryType, req.GetQuery().GetQueryType())
+			if req.GetExecution().GetWorkflowId() == "wid-3" {
+				return nil, serviceerror.NewDeadlineExceeded("timeout")
+			}
+			return &workflowservice.QueryWorkflowResponse{QueryResult: payloads.EncodeString("blocked")}, nil
+		}).Times(3)
+
+	err := s.app.Run([]string{"", "--namespace", cliTestNamespace, "workflow", "stack", "--query", "ExecutionStatus='Running'", "--concurrency", "2"})
+	s.NoError(err)
+	s.sdkClient.AssertExpectations(s.T())
+}
+
+func (s *cliAppSuite) TestQueryWorkflowUsingStackTrace_Limit() {
+	for _, tc := range []struct {
+		limit        string
+		queried      int
+		limitReached bool
+	}{
+		{limit: "2", queried: 2, limitReached: true},
+		{limit: "3", queried: 3, limitReached: false},
+	} {
+		var executions []*workflowpb.WorkflowExecutionInfo
+		for _, wid := range []string{"wid-1", "wid-2", "wid-3"} {
+			executions = append(executions, &workflowpb.WorkflowExecutionInfo{
+				Execution: &commonpb.WorkflowExecution{WorkflowId: wid, RunId: "rid"},
+			})
+		}
+		s.sdkClient.On("ListWorkflow", mock.Anything, mock.Anything).Return(&workflowservice.ListWorkflowExecutionsResponse{
+			Executions: executions,
+		}, nil).Once()
+		s.frontendClient.EXPECT().QueryWorkflow(gomock.Any(), gomock.Any()).
+			Return(&workflowservice.QueryWorkflowResponse{QueryResult: payloads.EncodeString("blocked")}, nil).Times(tc.queried)
+
+		out := captureStdout(s.T(), func() {
+			err := s.app.Run([]string{"", "--namespace", cliTestNamespace, "workflow", "stack", "--query", "ExecutionStatus='Running'", "--limit", tc.limit})
+			s.NoError(err)
+		})
+		s.Equal(tc.limitReached, strings.Contains(out, "limit reached"), out)
+	}
+}
+
+func (s *cliAppSuite) TestQueryWorkflowUsingStackTrace_InvalidLimit() {
+	errorCode := s.RunWithExitCode([]string{"", "--namespace", cliTestNamespace, "workflow", "stack", "--query", "ExecutionStatus='Running'", "--limit", "0"})
+	s.Equal(1, errorCode)
+}
+
+func (s *cliAppSuite) TestQueryWorkflowUsingStackTrace_RequiresWorkflowOrQuery() {
+	errorCode := s.RunWithExitCode([]string{"", "--namespace", cliTestNamespace, "workflow", "stack"})
+	s.Equal(1, errorCode)
+}
+
+func captureStdout(t *testing.T, fn func()) string {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan string)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- string(out)
+	}()
+	fn()
+	require.NoError(t, w.Close())
+	return <-done
+}
@@ -25,7 +25,10 @@
 package cli
 
 import (
+	"fmt"
+
 	"github.com/temporalio/tctl-kit/pkg/flags"
+	"github.com/temporalio/tctl-kit/pkg/format"
 	"github.com/temporalio/tctl-kit/pkg/output"
 	"github.com/urfave/cli/v2"
 )
@@ -54,6 +57,37 @@ func newTaskQueueCommands() []*cli.Command {
 				return DescribeTaskQueue(c)
 			},
 		},
+		{
+			Name:  "status",
+			Usage: "Show workflow and activity pollers, backlog and partitions of a task queue",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     FlagTaskQueue,
+					Aliases:  FlagTaskQueueAlias,
+					Usage:    "Task Queue name",
+					Required: true,
+				},
+				&cli.IntFlag{
+					Name:  FlagStaleAfter,
+					Value: defaultStalePollerSeconds,
+					Usage: "Flag pollers that haven't polled for this many seconds as stale",
+				},
+				&cli.StringFlag{
+					Name:    output.FlagOutput,
+					Aliases: []string{"o"},
+					Usage:   fmt.Sprintf("format output as: %v, %v.", output.Table, output.JSON),
+					Value:   string(output.Table),
+				},
+				&cli.StringFlag{
+					Name:  format.FlagTimeFormat,
+					Usage: fmt.Sprintf("format time as: %v, %v, %v.", format.Relative, format.ISO, format.Raw),
+					Value: string(format.Relative),
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return TaskQueueStatus(c)
+			},
+		},
 		{
 			Name:    "list-partition",
 			Aliases: []string{"lp"},
@@ -26,6 +26,9 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"sort"
+	"time"
 
 	enumspb "go.temporal.io/api/enums/v1"
 	taskqueuepb "go.temporal.io/api/taskqueue/v1"
@@ -66,7 +69,10 @@ func DescribeTaskQueue(c *cli.Context) error {
 
 // ListTaskQueuePartitions gets all the taskqueue partition and host information.
 func ListTaskQueuePartitions(c *cli.Context) error {
-	frontendClient := cFactory.FrontendClient(c)
+	frontendClient, err := cFactory.FrontendClient(c)
+	if err != nil {
+		return err
+	}
 	namespace, err := getRequiredGlobalOption(c, FlagNamespace)
 	if err != nil {
 		return err
@@ -110,3 +116,157 @@ func ListTaskQueuePartitions(c *cli.Context) error {
 	output.PrintItems(c, items, optsA)
 	return nil
 }
+
+// taskQueueTypeStatus summarizes the workflow or the activity task queue
+type taskQueueTypeStatus struct {
+	Type         string
+	Pollers      int
+	StalePollers int
+	// BacklogCountHint is only reported by servers that support task queue status
+	BacklogCountHint string
+	Partitions       int
+}
+
+// taskQueuePoller is a worker with the time it last polled each task queue type
+type taskQueuePoller struct {
+	Identity             string
+	LastWorkflowTaskPoll *time.Time
+	LastActivityTaskPoll *time.Time
+	Stale                bool
+}
+
+type taskQueuePartition struct {
+	Type          string
+	Key           string
+	OwnerHostName string
+}
+
+type taskQueueStatusOutput struct {
+	Status     []interface{}
+	Pollers    []interface{}
+	Partitions []interface{}
+}
+
+// TaskQueueStatus shows the workflow and activity pollers, backlog and partitions of a task queue
+func TaskQueueStatus(c *cli.Context) error {
+	frontendClient, err := cFactory.FrontendClient(c)
+	if err != nil {
+		return err
+	}
+	namespace, err := getRequiredGlobalOption(c, FlagNamespace)
+	if err != nil {
+		return err
+	}
+	taskQueue := &taskqueuepb.TaskQueue{
+		Name: c.String(FlagTaskQueue),
+		Kind: enumspb.TASK_QUEUE_KIND_NORMAL,
+	}
+	staleAfter := time.Duration(c.Int(FlagStaleAfter)) * time.Second
+
+	ctx, cancel := newContext(c)
+	defer cancel()
+
+	partitionsResp, err := frontendClient.ListTaskQueuePartitions(ctx, &workflowservice.ListTaskQueuePartitionsRequest{
+		Namespace: namespace,
+		TaskQueue: taskQueue,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list task queue partitions.\n%s", err)
+	}
+
+	now := time.Now()
+	var out taskQueueStatusOutput
+	pollers := map[string]*taskQueuePoller{}
+	for _, taskQueueType := range []enumspb.TaskQueueType{enumspb.TASK_QUEUE_TYPE_WORKFLOW, enumspb.TASK_QUEUE_TYPE_ACTIVITY} {
+		resp, err := frontendClient.DescribeTaskQueue(ctx, &workflowservice.DescribeTaskQueueRequest{
+			Namespace:              namespace,
+			TaskQueue:              taskQueue,
+			TaskQueueType:          taskQueueType,
+			IncludeTaskQueueStatus: true,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to describe task queue.\n%s", err)
+		}
+
+		partitions := partitionsResp.GetWorkflowTaskQueuePartitions()
+		if taskQueueType == enumspb.TASK_QUEUE_TYPE_ACTIVITY {
+			partitions = partitionsResp.GetActivityTaskQueuePartitions()
+		}
+		status := taskQueueTypeStatus{
+			Type:             taskQueueType.String(),
+			Pollers:          len(resp.GetPollers()),
+			BacklogCountHint: "unknown",
+			Partitions:       len(partitions),
+		}
+		if resp.GetTaskQueueStatus() != nil {
+			status.BacklogCountHint = fmt.Sprintf("%d", resp.GetTaskQueueStatus().GetBacklogCountHint())
+		}
+
+		for _, p := range resp.GetPollers() {
+			poller, ok := pollers[p.GetIdentity()]
+			if !ok {
+				poller = &taskQueuePoller{Identity: p.GetIdentity()}
+				pollers[p.GetIdentity()] = poller
+			}
+
+			lastAccess := p.GetLastAccessTime()
+			if taskQueueType == enumspb.TASK_QUEUE_TYPE_ACTIVITY {
+				poller.LastActivityTaskPoll = lastAccess
+			} else {
+				poller.LastWorkflowTaskPoll = lastAccess
+			}
+			if isStalePoller(p, now, staleAfter) {
+				poller.Stale = true
+				status.StalePollers++
+			}
+		}
+
+		out.Status = append(out.Status, status)
+		for _, partition := range partitions {
+			out.Partitions = append(out.Partitions, taskQueuePartition{
+				Type:          taskQueueType.String(),
+				Key:           partition.GetKey(),
+				OwnerHostName: partition.GetOwnerHostName(),
+			})
+		}
+	}
+
+	identities := make([]string, 0, len(pollers))
+	for identity := range pollers {
+		identities = append(identities, identity)
+	}
+	sort.Strings(identities)
+	for _, identity := range identities {
+		out.Pollers = append(out.Pollers, pollers[identity])
+	}
+
+	if c.String(output.FlagOutput) == string(output.JSON) {
+		output.PrintJSON(c, out, &output.PrintOptions{Pager: os.Stdout})
+		return nil
+	}
+
+	fmt.Println(color.Magenta(c, "Task Queue Status\n"))
+	output.PrintItems(c, out.Status, &output.PrintOptions{
+		Fields: []string{"Type", "Pollers", "StalePollers", "BacklogCountHint", "Partitions"},
+	})
+	fmt.Println(color.Magenta(c, "\nPollers\n"))
+	output.PrintItems(c, out.Pollers, &output.PrintOptions{
+		Fields: []string{"Identity", "LastWorkflowTaskPoll", "LastActivityTaskPoll", "Stale"},
+	})
+	fmt.Println(color.Magenta(c, "\nPartitions\n"))
+	output.PrintItems(c, out.Partitions, &output.PrintOptions{
+		Fields: []string{"Type", "Key", "OwnerHostName"},
+	})
+
+	return nil
+}
+
+// isStalePoller reports whether the poller hasn't polled for longer than a long poll takes
+func isStalePoller(poller *taskqueuepb.PollerInfo, now time.Time, staleAfter time.Duration) bool {
+	lastAccess := poller.GetLastAccessTime()
+	if lastAccess == nil {
+		return true
+	}
+
+	return now.Sub(*lastAccess) > staleAfter
+}
@@ -23,37 +23,108 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/urfave/cli/v2"
 )
 
+// maxAliasDepth limits how many aliases can refer to each other
+const maxAliasDepth = 10
+
+const aliasesHelpTemplate = `{{with index .Metadata "aliases"}}
+ALIASES:{{range .}}
+   {{.}}{{end}}
+{{end}}`
+
 func useDynamicCommands(app *cli.App) {
 	app.CommandNotFound = func(ctx *cli.Context, cmdToFind string) {
 		// try execute as an alias command
-		cmdValue, err := lookupCmdInAliasCommands(ctx, cmdToFind)
-		if err == nil {
-			_ = executeAliasCommand(ctx, app, cmdValue)
+		if _, err := lookupCmdInAliasCommands(cmdToFind); err == nil {
+			if err := executeAliasCommand(ctx, app); err != nil {
+				handleError(ctx, err)
+			}
 			return
 		}
 
 		// execute external binary by path
-		pluginName := "tctl-" + cmdToFind
-		path, err := exec.LookPath(pluginName)
+		path, err := exec.LookPath(pluginPrefix + cmdToFind)
 		if err == nil {
-			os.Args = append([]string{pluginName}, os.Args[2:]...)
-			_ = executePlugin(ctx, path, os.Args, os.Environ())
+			// only returns if the plugin couldn't be started
+			if err := runPlugin(ctx, cmdToFind, path, ctx.Args().Tail()); err != nil {
+				handleError(ctx, err)
+			}
+			return
 		}
 
-		fmt.Fprintf(os.Stderr, "%s is not a command. See '%s --help\n'", cmdToFind, ctx.App.Name)
+		fmt.Fprintf(os.Stderr, "%s is not a command. See '%s --help'\n", cmdToFind, ctx.App.Name)
+	}
+
+	if app.Metadata == nil {
+		app.Metadata = map[string]interface{}{}
+	}
+	app.Metadata["aliases"] = aliasHelpLines()
+	app.Metadata["plugins"] = pluginNames{}
+	app.CustomAppHelpTemplate = cli.AppHelpTemplate + aliasesHelpTemplate + pluginsHelpTemplate
+
+	app.EnableBashCompletion = true
+	app.BashComplete = func(c *cli.Context) {
+		cli.DefaultAppComplete(c)
+		for _, name := range aliasNames() {
+			fmt.Fprintln(c.App.Writer, name)
+		}
+		for _, name := range (pluginNames{}).Names() {
+			fmt.Fprintln(c.App.Writer, name)
+		}
 	}
 }
 
+// aliasNames returns the sorted names of the configured aliases
+func aliasNames() []string {
+	if tctlConfig == nil {
+		return nil
+	}
+
+	aliases, err := tctlConfig.GetAliases()
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// aliasHelpLines returns the aliases formatted for the app help
+func aliasHelpLines() []string {
+	names := aliasNames()
+	if len(names) == 0 {
+		return nil
+	}
+
+	aliases, _ := tctlConfig.GetAliases()
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		lines = append(lines, name+"\t"+aliases[name])
+	}
+	return lines
+}
+
 // looks up an alias command in tctl config and returns its value
-func lookupCmdInAliasCommands(ctx *cli.Context, cmd string) (string, error) {
+func lookupCmdInAliasCommands(cmd string) (string, error) {
+	if tctlConfig == nil {
+		return "", fmt.Errorf("alias command %s not found", cmd)
+	}
+
 	aliases, err := tctlConfig.GetAliases()
 	if err != nil {
 		return "", err
@@ -68,10 +139,171 @@ func lookupCmdInAliasCommands(ctx *cli.Context, cmd string) (string, error) {
 	return "", fmt.Errorf("alias command %s not found", cmd)
 }
 
-func executeAliasCommand(ctx *cli.Context, app *cli.App, aliasVal string) error {
-	passedArgs := ctx.Args().Slice()
-	aliasArgs := strings.Split(aliasVal, " ")
-	args := append(passedArgs, aliasArgs...)
+// executeAliasCommand runs the app again with the alias expanded. Global flags passed before the alias are kept.
+func executeAliasCommand(ctx *cli.Context, app *cli.App) error {
+	// ctx.Args() holds the alias name followed by the arguments passed to it
+	aliasArgs := ctx.Args().Slice()
+	args, err := resolveAlias(app, aliasArgs[0], aliasArgs[1:])
+	if err != nil {
+		return err
+	}
+
+	return app.Run(append(globalArgs(ctx), args...))
+}
+
+// globalArgs returns the program name followed by the global flags that are set, so that they can be passed to app.Run again
+func globalArgs(ctx *cli.Context) []string {
+	args := []string{ctx.App.Name}
+	for _, f := range ctx.App.Flags {
+		name := f.Names()[0]
+		if !ctx.IsSet(name) {
+			continue
+		}
 
-	return app.Run(args)
+		if _, ok := f.(*cli.StringSliceFlag); ok {
+			for _, v := range ctx.StringSlice(name) {
+				args = append(args, "--"+name, v)
+			}
+			continue
+		}
+		args = append(args, fmt.Sprintf("--%s=%v", name, ctx.Value(name)))
+	}
+	return args
+}
+
+// resolveAlias expands the alias, and any alias it starts with, into the arguments of a command
+func resolveAlias(app *cli.App, alias string, passedArgs []string) ([]string, error) {
+	chain := []string{alias}
+	args := append([]string{alias}, passedArgs...)
+	for {
+		aliasVal, err := lookupCmdInAliasCommands(args[0])
+		if err != nil {
+			return args, nil
+		}
+
+		aliasArgs, err := splitCommandLine(aliasVal)
+		if err != nil {
+			return nil, fmt.Errorf("invalid alias %s: %s", args[0], err)
+		}
+		if len(aliasArgs) == 0 {
+			return nil, fmt.Errorf("alias %s is empty", args[0])
+		}
+
+		args, err = expandAliasArgs(aliasArgs, args[1:])
+		if err != nil {
+			return nil, fmt.Errorf("unable to expand alias %s: %s", chain[len(chain)-1], err)
+		}
+
+		// built-in commands take precedence over aliases
+		if app.Command(args[0]) != nil {
+			return args, nil
+		}
+		for _, a := range chain {
+			if a == args[0] {
+				return nil, fmt.Errorf("alias %s refers to itself: %s", alias, strings.Join(append(chain, args[0]), " -> "))
+			}
+		}
+		if len(chain) >= maxAliasDepth {
+			return nil, fmt.Errorf("alias %s refers to more than %d aliases", alias, maxAliasDepth)
+		}
+		chain = append(chain, args[0])
+	}
+}
+
+// expandAliasArgs substitutes $1..$N and $@ placeholders with the passed arguments.
+// If the alias has no placeholders, the passed arguments are appended to the alias.
+func expandAliasArgs(aliasArgs []string, passedArgs []string) ([]string, error) {
+	var expanded []string
+	usesPlaceholders := false
+	for _, arg := range aliasArgs {
+		if arg == "$@" {
+			usesPlaceholders = true
+			expanded = append(expanded, passedArgs...)
+			continue
+		}
+
+		var b strings.Builder
+		for i := 0; i < len(arg); i++ {
+			if arg[i] != '$' || i+1 == len(arg) || arg[i+1] < '1' || arg[i+1] > '9' {
+				b.WriteByte(arg[i])
+				continue
+			}
+
+			j := i + 1
+			for j < len(arg) && arg[j] >= '0' && arg[j] <= '9' {
+				j++
+			}
+			n, _ := strconv.Atoi(arg[i+1 : j])
+			if n > len(passedArgs) {
+				return nil, fmt.Errorf("missing argument $%d", n)
+			}
+			b.WriteString(passedArgs[n-1])
+			usesPlaceholders = true
+			i = j - 1
+		}
+		expanded = append(expanded, b.String())
+	}
+
+	if !usesPlaceholders {
+		expanded = append(expanded, passedArgs...)
+	}
+	return expanded, nil
+}
+
+// splitCommandLine splits a command line into arguments like a POSIX shell does,
+// supporting single quotes, double quotes and backslash escapes
+func splitCommandLine(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else if r == '\\' && i+1 < len(runes) && strings.ContainsRune(`"\$`+"`", runes[i+1]) {
+				i++
+				current.WriteRune(runes[i])
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == '\\':
+			if i+1 == len(runes) {
+				return nil, errors.New("trailing backslash")
+			}
+			i++
+			current.WriteRune(runes[i])
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
 }
@@ -0,0 +1,92 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/temporalio/tctl/config"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{line: "workflow list", want: []string{"workflow", "list"}},
+		{line: "  workflow   list  ", want: []string{"workflow", "list"}},
+		{line: `workflow list --query "WorkflowType='foo' and StartTime > '2022-01-01'"`, want: []string{"workflow", "list", "--query", "WorkflowType='foo' and StartTime > '2022-01-01'"}},
+		{line: `workflow list --query 'WorkflowType="foo"'`, want: []string{"workflow", "list", "--query", `WorkflowType="foo"`}},
+		{line: `echo "a \"quoted\" word" b\ c ''`, want: []string{"echo", `a "quoted" word`, "b c", ""}},
+	}
+
+	for _, tt := range tests {
+		got, err := splitCommandLine(tt.line)
+		require.NoError(t, err, tt.line)
+		require.Equal(t, tt.want, got, tt.line)
+	}
+
+	for _, line := range []string{`"unterminated`, `'unterminated`, `trailing\`} {
+		_, err := splitCommandLine(line)
+		require.Error(t, err, line)
+	}
+}
+
+func TestExpandAliasArgs(t *testing.T) {
+	args, err := expandAliasArgs([]string{"workflow", "list"}, []string{"--output", "json"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"workflow", "list", "--output", "json"}, args)
+
+	args, err = expandAliasArgs([]string{"workflow", "describe", "-w", "$1", "--run-id", "$2"}, []string{"wid", "rid"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"workflow", "describe", "-w", "wid", "--run-id", "rid"}, args)
+
+	args, err = expandAliasArgs([]string{"workflow", "list", "--query", "WorkflowType='$1'", "$@"}, []string{"foo", "--limit", "10"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"workflow", "list", "--query", "WorkflowType='foo'", "foo", "--limit", "10"}, args)
+
+	_, err = expandAliasArgs([]string{"cost", "$10"}, []string{"a"})
+	require.Error(t, err)
+
+	_, err = expandAliasArgs([]string{"workflow", "describe", "-w", "$1"}, nil)
+	require.Error(t, err)
+}
+
+func (s *cliAppSuite) TestExecuteAliasCommand() {
+	s.T().Setenv("HOME", s.T().TempDir())
+	defer func(cfg *config.Config) { tctlConfig = cfg }(tctlConfig)
+	var err error
+	tctlConfig, err = config.NewTctlConfig()
+	s.NoError(err)
+	s.NoError(tctlConfig.SetAlias("wt", "workflow terminate --reason $2 --wid $1"))
+
+	s.sdkClient.On("TerminateWorkflow", mock.Anything, "wid", "", "done", mock.Anything).Return(nil).Once()
+
+	// the arguments differ from os.Args, the global flags passed before the alias are kept
+	err = s.app.Run([]string{"", "--namespace", cliTestNamespace, "--grpc-meta", "tenant=acme", "wt", "wid", "done"})
+	s.Nil(err)
+	s.sdkClient.AssertExpectations(s.T())
+}
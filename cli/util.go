@@ -25,8 +25,10 @@
 package cli
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"regexp"
@@ -39,6 +41,7 @@ import (
 	"github.com/urfave/cli/v2"
 	commonpb "go.temporal.io/api/common/v1"
 	enumspb "go.temporal.io/api/enums/v1"
+	failurepb "go.temporal.io/api/failure/v1"
 	historypb "go.temporal.io/api/history/v1"
 	sdkclient "go.temporal.io/sdk/client"
 	"go.temporal.io/sdk/converter"
@@ -51,9 +54,44 @@ import (
 )
 
 // HistoryEventToString convert HistoryEvent to string
-func HistoryEventToString(e *historypb.HistoryEvent, printFully bool, maxFieldLength int) string {
+func HistoryEventToString(c *cli.Context, e *historypb.HistoryEvent, printFully bool, maxFieldLength int) string {
+	s, failure := eventAttributesToString(e, printFully, maxFieldLength)
+	if failure == nil {
+		return s
+	}
+	if s != "" {
+		s += "\n"
+	}
+	return fmt.Sprintf("%s%s:\n%s", s, color.RedString("Failure"), formatFailure(c, failure, "  "))
+}
+
+// historyEventToPlainString is like HistoryEventToString with printFully, without terminal colors
+func historyEventToPlainString(e *historypb.HistoryEvent) string {
+	// stringify colors the field names unless colors are disabled
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	s, failure := eventAttributesToString(e, true, 0)
+	if failure == nil {
+		return s
+	}
+	if s != "" {
+		s += "\n"
+	}
+	return fmt.Sprintf("%sFailure:\n%s", s, formatFailurePlain(failure, "  "))
+}
+
+// eventAttributesToString renders the attributes of an event except its failure, which is returned to be rendered
+// with its cause chain and decoded details
+func eventAttributesToString(e *historypb.HistoryEvent, printFully bool, maxFieldLength int) (string, *failurepb.Failure) {
 	data := getEventAttributes(e)
-	return stringify.AnyToString(data, printFully, maxFieldLength, customDataConverter())
+	failure := eventFailure(e)
+	if failure != nil {
+		data = eventAttributesWithoutFailure(e)
+	}
+
+	return stringify.AnyToString(data, printFully, maxFieldLength, customDataConverter()), failure
 }
 
 // ColorEvent takes an event and return string with color
@@ -346,6 +384,17 @@ func prettyPrintJSONObject(o interface{}) {
 	fmt.Println()
 }
 
+// promptYes asks the user for confirmation and returns whether they answered yes
+func promptYes() (bool, error) {
+	fmt.Print("Please confirm[Yes/No]:")
+	text, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false, err
+	}
+
+	return strings.EqualFold(strings.TrimSpace(text), "yes"), nil
+}
+
 func mapKeysToArray(m map[string]interface{}) []string {
 	var out []string
 	for k := range m {
@@ -370,23 +419,124 @@ func getRequiredGlobalOption(c *cli.Context, optionName string) (string, error)
 	return value, nil
 }
 
+// readFlagOrConfig returns the flag value if it's set, otherwise the config value.
+// Secret references in the config are resolved; if that fails, a warning is printed and the value is empty.
 func readFlagOrConfig(c *cli.Context, key string) string {
+	val, err := resolveFlagOrConfig(c, key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s unable to resolve %s: %s\n", color.YellowString("Warning:"), key, err)
+		return ""
+	}
+
+	return val
+}
+
+// resolveFlagOrConfig is like readFlagOrConfig, but returns an error if a secret reference can't be resolved
+func resolveFlagOrConfig(c *cli.Context, key string) (string, error) {
 	if c.IsSet(key) {
-		return c.String(key)
+		return c.String(key), nil
+	}
+
+	if cVal := readConfig(c, key); cVal != "" {
+		if isSecretKey(key) && isSecretReference(cVal) {
+			return resolveSecret(cVal)
+		}
+		if err := checkConfigReference(key, cVal); err != nil {
+			return "", err
+		}
+		return cVal, nil
+	}
+
+	return c.String(key), nil
+}
+
+// hasFlagOrConfig returns whether the flag is set or has a value in the config, without resolving secrets
+func hasFlagOrConfig(c *cli.Context, key string) bool {
+	return c.IsSet(key) || readConfig(c, key) != ""
+}
+
+// readConfig returns the raw config value of a root key or of a key in the selected environment
+func readConfig(c *cli.Context, key string) string {
+	if tctlConfig == nil {
+		return ""
 	}
 
 	var cVal string
 	if isRootKey(key) {
 		cVal, _ = tctlConfig.Get(c, key)
 	} else if isEnvKey(key) {
-		cVal, _ = tctlConfig.GetByEnvironment(c, key)
+		cVal, _ = tctlConfig.Get(c, envConfigKey(selectedEnvironment(c), key))
 	}
+	return cVal
+}
+
+// readGRPCMeta returns the gRPC metadata passed with --grpc-meta or loaded from the environment
+func readGRPCMeta(c *cli.Context) (map[string]string, error) {
+	return parseGRPCMeta(c.StringSlice(FlagGRPCMeta))
+}
+
+// parseGRPCMeta parses key=value pairs into gRPC metadata
+func parseGRPCMeta(values []string) (map[string]string, error) {
+	meta := make(map[string]string, len(values))
+	for _, kv := range values {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid %s %q: expected format key=value", FlagGRPCMeta, kv)
+		}
 
-	if cVal != "" {
-		return cVal
+		key := strings.ToLower(strings.TrimSpace(parts[0]))
+		if err := validateGRPCMetaKey(key); err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %s", FlagGRPCMeta, kv, err)
+		}
+		if err := validateGRPCMetaValue(parts[1]); err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %s", FlagGRPCMeta, kv, err)
+		}
+
+		meta[key] = parts[1]
+	}
+
+	return meta, nil
+}
+
+// validateGRPCMetaKey checks that the key is a valid custom gRPC metadata key, see
+// https://github.com/grpc/grpc/blob/master/doc/PROTOCOL-HTTP2.md
+func validateGRPCMetaKey(key string) error {
+	if key == "" {
+		return errors.New("key is empty")
+	}
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return fmt.Errorf("key contains invalid character %q, allowed are: 0-9 a-z - _ .", r)
+		}
+	}
+	if strings.HasPrefix(key, "grpc-") {
+		return errors.New("keys starting with \"grpc-\" are reserved by gRPC")
+	}
+	if strings.HasSuffix(key, "-bin") {
+		return errors.New("binary keys are not supported")
+	}
+
+	return nil
+}
+
+// validateGRPCMetaValue checks that the value consists of printable ASCII characters other than comma,
+// which separates the values in the config and in TEMPORAL_CLI_GRPC_META
+func validateGRPCMetaValue(value string) error {
+	for _, r := range value {
+		if r < 0x20 || r > 0x7E {
+			return fmt.Errorf("value contains invalid character %q, only printable ASCII is allowed", r)
+		}
+		if r == ',' {
+			return errors.New("value can't contain commas")
+		}
 	}
 
-	return c.String(key)
+	return nil
 }
 
 func formatTime(t time.Time, onlyTime bool) string {
@@ -29,6 +29,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"go.temporal.io/server/common/payloads"
 )
 
 func (s *utilSuite) SetupTest() {
@@ -89,3 +90,38 @@ func (s *utilSuite) TestStringToEnum_MapEmptyEnum() {
 	s.Error(err)
 	s.Equal(result, int32(0))
 }
+
+func (s *utilSuite) TestParseGRPCMeta() {
+	meta, err := parseGRPCMeta([]string{"Tenant-ID=acme", "x-trace=a=b", ""})
+	s.NoError(err)
+	s.Equal(map[string]string{"tenant-id": "acme", "x-trace": "a=b"}, meta)
+}
+
+func (s *utilSuite) TestParseGRPCMeta_Invalid() {
+	for _, kv := range []string{
+		"no-value",
+		"=value",
+		"bad key=value",
+		"grpc-timeout=1s",
+		"trace-bin=AAEC",
+		"key=line\nbreak",
+		"tracestate=a=1,b=2",
+	} {
+		_, err := parseGRPCMeta([]string{kv})
+		s.Error(err, kv)
+	}
+}
+
+func TestDecodePayloads(t *testing.T) {
+	single, err := payloads.Encode("stack trace")
+	require.NoError(t, err)
+	require.Equal(t, "stack trace", decodePayloads(single))
+	require.Equal(t, "stack trace", formatDecodedPayloads(decodePayloads(single)))
+
+	multiple, err := payloads.Encode("a", 1)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"a", float64(1)}, decodePayloads(multiple))
+	require.Equal(t, "[\n  \"a\",\n  1\n]", formatDecodedPayloads(decodePayloads(multiple)))
+
+	require.Nil(t, decodePayloads(nil))
+}
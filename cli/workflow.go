@@ -25,9 +25,11 @@
 package cli
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/temporalio/tctl-kit/pkg/flags"
+	"github.com/temporalio/tctl-kit/pkg/output"
 	"github.com/urfave/cli/v2"
 )
 
@@ -50,6 +52,10 @@ func newWorkflowCommands() []*cli.Command {
 					Name:  FlagResetPointsOnly,
 					Usage: "Only show auto-reset points",
 				},
+				&cli.Int64Flag{
+					Name:  FlagAtEvent,
+					Usage: "Show the pending activities, timers, children, signals received and last completed workflow task right after this event Id, rebuilt from the history",
+				},
 			}...),
 			Action: func(c *cli.Context) error {
 				return DescribeWorkflow(c)
@@ -81,15 +87,157 @@ func newWorkflowCommands() []*cli.Command {
 				return ShowHistory(c)
 			},
 		},
+		{
+			Name:  "wait",
+			Usage: "Wait for a workflow execution to close and exit with a code that reflects its status",
+			Description: "Exit codes: 0 completed, 2 failed, 3 timed out, 4 canceled, 5 terminated, 6 continued as new, " +
+				"7 still running after --timeout, 1 any other error",
+			Flags: append(flagsForExecution,
+				&cli.IntFlag{
+					Name:  FlagWaitTimeout,
+					Usage: "Maximum time to wait in seconds, 0 waits until the workflow closes",
+				},
+				flagFollowRuns,
+			),
+			Action: func(c *cli.Context) error {
+				return WaitForWorkflow(c)
+			},
+		},
+		{
+			Name:        "result",
+			Usage:       "Print the result or the failure of a closed workflow execution",
+			Description: "The exit code reflects the status of the workflow execution, as for \"workflow wait\"",
+			Flags:       append(flagsForExecution, flagFollowRuns),
+			Action: func(c *cli.Context) error {
+				return WorkflowResult(c)
+			},
+		},
+		{
+			Name:  "tree",
+			Usage: "Show the tree of child workflows started by a workflow execution",
+			Flags: append(flagsForExecution,
+				&cli.IntFlag{
+					Name:  FlagDepth,
+					Value: defaultWorkflowTreeDepth,
+					Usage: "Maximum depth of child workflows to walk",
+				},
+				&cli.StringFlag{
+					Name:  FlagTreeFormat,
+					Value: treeFormatText,
+					Usage: "Format of the tree: " + strings.Join(treeFormats, ", ") + ". DOT is rendered by Graphviz, Mermaid by Markdown viewers such as GitHub",
+				},
+			),
+			Action: func(c *cli.Context) error {
+				return ShowWorkflowTree(c)
+			},
+		},
+		{
+			Name:  "timeline",
+			Usage: "Show the activities, timers and child workflows of a workflow execution as a Gantt chart",
+			Flags: append(flagsForExecution,
+				&cli.IntFlag{
+					Name:  FlagWidth,
+					Usage: "Width of the chart in columns, defaults to the width of the terminal",
+				},
+				&cli.StringFlag{
+					Name:    FlagOutputFilename,
+					Aliases: FlagOutputFilenameAlias,
+					Usage:   "Write the timeline to a self-contained HTML file instead",
+				},
+			),
+			Action: func(c *cli.Context) error {
+				return ShowWorkflowTimeline(c)
+			},
+		},
+		{
+			Name:  "report",
+			Usage: "Write a self-contained HTML report of a workflow execution, to attach to incident postmortems",
+			Flags: append(flagsForExecution,
+				&cli.StringFlag{
+					Name:     FlagOut,
+					Usage:    "HTML file to write the report to",
+					Required: true,
+				},
+			),
+			Action: func(c *cli.Context) error {
+				return WorkflowReport(c)
+			},
+		},
+		{
+			Name:  "diagnose",
+			Usage: "Report known problems of a workflow execution, such as non-determinism or missing workers, and how to fix them",
+			Flags: append(flagsForExecution,
+				&cli.StringFlag{
+					Name:    output.FlagOutput,
+					Aliases: []string{"o"},
+					Usage:   fmt.Sprintf("format output as: %v, %v.", output.Table, output.JSON),
+					Value:   string(output.Table),
+				},
+			),
+			Action: func(c *cli.Context) error {
+				return DiagnoseWorkflow(c)
+			},
+		},
+		{
+			Name: "find-stuck",
+			Usage: "Scan the workflows matching a query and report the ones that look stuck, one JSON per line. " +
+				"The report can be used as input file of reset-batch and terminate",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    FlagListQuery,
+					Aliases: FlagListQueryAlias,
+					Usage:   FlagListQueryUsage,
+					Value:   "ExecutionStatus='Running'",
+				},
+				&cli.IntFlag{
+					Name:  FlagMaxWorkflowTaskAttempts,
+					Value: defaultFindStuckMaxWorkflowTaskAttempts,
+					Usage: "Report workflows whose workflow task has been attempted more times, 0 disables the check",
+				},
+				&cli.IntFlag{
+					Name:  FlagMaxActivityAttempts,
+					Value: defaultFindStuckMaxActivityAttempts,
+					Usage: "Report workflows with a pending activity attempted more times, 0 disables the check",
+				},
+				&cli.StringFlag{
+					Name:  FlagNoProgress,
+					Value: defaultFindStuckNoProgress,
+					Usage: "Report workflows without new history events for longer, such as 30m or 2d, 0 disables the check. " +
+						"Workflows waiting on long timers or signals are reported too",
+				},
+				&cli.Int64Flag{
+					Name:  FlagHistoryLength,
+					Value: defaultFindStuckHistoryLength,
+					Usage: "Report workflows with at least this many history events, 0 disables the check",
+				},
+				&cli.IntFlag{
+					Name:  FlagConcurrency,
+					Value: defaultStackTraceConcurrency,
+					Usage: "Number of workflows to inspect in parallel",
+				},
+				&cli.IntFlag{
+					Name:  FlagRPS,
+					Value: defaultFindStuckRPS,
+					Usage: "Maximum requests per second to the server, shared by scan and describe calls",
+				},
+				&cli.StringFlag{
+					Name:    FlagOutputFilename,
+					Aliases: FlagOutputFilenameAlias,
+					Usage:   "File to write the report to, default to stdout",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return FindStuckWorkflows(c)
+			},
+		},
 		{
 			Name:  "query",
 			Usage: "Query workflow execution",
 			Flags: append(flagsForStackTraceQuery,
 				&cli.StringFlag{
-					Name:     FlagQueryType,
-					Aliases:  FlagQueryTypeAlias,
-					Usage:    "The query type you want to run",
-					Required: true,
+					Name:    FlagQueryType,
+					Aliases: FlagQueryTypeAlias,
+					Usage:   "The query type you want to run. If omitted, the query types registered by the workflow are listed, if its SDK reports them",
 				}),
 			Action: func(c *cli.Context) error {
 				return QueryWorkflow(c)
@@ -99,7 +247,7 @@ func newWorkflowCommands() []*cli.Command {
 		{
 			Name:  "stack",
 			Usage: "Query workflow execution with __stack_trace as query type",
-			Flags: flagsForStackTraceQuery,
+			Flags: flagsForStack,
 			Action: func(c *cli.Context) error {
 				return QueryWorkflowUsingStackTrace(c)
 			},
@@ -157,13 +305,41 @@ func newWorkflowCommands() []*cli.Command {
 		{
 			Name:  "terminate",
 			Usage: "Terminate a new workflow execution",
-			Flags: append(flagsForExecution, []cli.Flag{
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    FlagWorkflowID,
+					Aliases: FlagWorkflowIDAlias,
+					Usage:   "Workflow ID. Either this or --input-file is required",
+				},
+				&cli.StringFlag{
+					Name:    FlagRunID,
+					Aliases: FlagRunIDAlias,
+					Usage:   "Run Id",
+				},
+				&cli.StringFlag{
+					Name:    FlagInputFile,
+					Aliases: FlagInputFileAlias,
+					Usage:   "Input file of the workflows to terminate, one per line of WorkflowId and RunId separated by tab, or of JSON such as the report of find-stuck",
+				},
 				&cli.StringFlag{
 					Name:    FlagReason,
 					Aliases: FlagReasonAlias,
 					Usage:   "The reason you want to terminate the workflow",
 				},
-			}...),
+				&cli.IntFlag{
+					Name:  FlagRPS,
+					Value: defaultTerminateRPS,
+					Usage: "Maximum terminations per second with --" + FlagInputFile,
+				},
+				&cli.BoolFlag{
+					Name:  FlagDryRun,
+					Usage: "List the workflows of --" + FlagInputFile + " without terminating them",
+				},
+				&cli.BoolFlag{
+					Name:  FlagYes,
+					Usage: "Terminate the workflows of --" + FlagInputFile + " without confirmation",
+				},
+			},
 			Action: func(c *cli.Context) error {
 				return TerminateWorkflow(c)
 			},
@@ -208,7 +384,7 @@ func newWorkflowCommands() []*cli.Command {
 				&cli.StringFlag{
 					Name:    FlagInputFile,
 					Aliases: FlagInputFileAlias,
-					Usage:   "Input file to use for resetting, one workflow per line of WorkflowId and RunId. RunId is optional, default to current runId if not specified. ",
+					Usage:   "Input file to use for resetting, one workflow per line of WorkflowId and RunId. RunId is optional, default to current runId if not specified. Lines can also be JSON such as the report of find-stuck",
 				},
 				&cli.StringFlag{
 					Name:    FlagListQuery,
@@ -49,6 +49,8 @@ import (
 	workflowpb "go.temporal.io/api/workflow/v1"
 	"go.temporal.io/api/workflowservice/v1"
 	sdkclient "go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"golang.org/x/time/rate"
 
 	"github.com/temporalio/tctl-kit/pkg/color"
 	"github.com/temporalio/tctl-kit/pkg/output"
@@ -57,6 +59,7 @@ import (
 	"go.temporal.io/server/common"
 	"go.temporal.io/server/common/backoff"
 	"go.temporal.io/server/common/clock"
+	"go.temporal.io/server/common/codec"
 	"go.temporal.io/server/common/collection"
 	"go.temporal.io/server/common/convert"
 	"go.temporal.io/server/common/primitives/timestamp"
@@ -276,6 +279,7 @@ func unmarshalMemoFromCLI(c *cli.Context) (map[string]interface{}, error) {
 }
 
 type historyIterator struct {
+	c    *cli.Context
 	iter interface {
 		HasNext() bool
 		Next() (*historypb.HistoryEvent, error)
@@ -300,38 +304,77 @@ func (h *historyIterator) Next() (interface{}, error) {
 		ID:      convert.Int64ToString(event.GetEventId()),
 		Time:    formatTime(timestamp.TimeValue(event.GetEventTime()), false),
 		Type:    ColorEvent(event),
-		Details: HistoryEventToString(event, false, h.maxFieldLength),
+		Details: HistoryEventToString(h.c, event, false, h.maxFieldLength),
 	}, nil
 }
 
 // helper function to print workflow progress with time refresh every second
 func printWorkflowProgress(c *cli.Context, wid, rid string, watch bool) error {
-	var maxFieldLength = c.Int(FlagMaxFieldLength)
 	sdkClient, err := getSDKClient(c)
 	if err != nil {
 		return err
 	}
+	followRuns := c.Bool(FlagFollowRuns)
 
 	tcCtx, cancel := newIndefiniteContext(c)
 	defer cancel()
 
+	if followRuns {
+		if rid, err = resolveRunID(tcCtx, sdkClient, wid, rid); err != nil {
+			return err
+		}
+	}
+
+	startTime := time.Now()
+	var runs []runOutcome
+	for {
+		if followRuns {
+			fmt.Println(color.Magenta(c, "Progress of run %s:", rid))
+		} else {
+			fmt.Println(color.Magenta(c, "Progress:"))
+		}
+		lastEvent, err := printRunProgress(tcCtx, c, sdkClient, wid, rid, watch, startTime)
+		if err != nil {
+			return err
+		}
+		runs = append(runs, runOutcome{RunID: rid, Status: runStatus(lastEvent)})
+
+		nextRID := nextRunID(lastEvent)
+		if !followRuns || nextRID == "" {
+			fmt.Println(color.Magenta(c, "\nResult:"))
+			if watch {
+				fmt.Printf("  Run Time: %d seconds\n", int(time.Since(startTime).Seconds()))
+			}
+			if followRuns {
+				printRunChain(runs)
+			}
+			printRunStatus(c, lastEvent)
+			return nil
+		}
+		fmt.Println()
+		rid = nextRID
+	}
+}
+
+// printRunProgress prints the events of a single run and returns the last one
+func printRunProgress(ctx context.Context, c *cli.Context, sdkClient sdkclient.Client, wid, rid string, watch bool, startTime time.Time) (*historypb.HistoryEvent, error) {
+	var maxFieldLength = c.Int(FlagMaxFieldLength)
+
 	doneChan := make(chan bool)
-	timeElapse := 1
 	isTimeElapseExist := false
-	ticker := time.NewTicker(time.Second).C
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
 	opts := &output.PrintOptions{
 		Fields:     []string{"ID", "Time", "Type"},
 		FieldsLong: []string{"Details"},
 	}
-	fmt.Println(color.Magenta(c, "Progress:"))
 	var lastEvent historypb.HistoryEvent // used for print result of this run
 
 	errChan := make(chan error)
 	go func() {
-		hIter := sdkClient.GetWorkflowHistory(tcCtx, wid, rid, watch, enumspb.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
-		iter := &historyIterator{iter: hIter, maxFieldLength: maxFieldLength, lastEvent: &lastEvent}
-		err = output.Pager(c, iter, opts)
-		if err != nil {
+		hIter := sdkClient.GetWorkflowHistory(ctx, wid, rid, watch, enumspb.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
+		iter := &historyIterator{c: c, iter: hIter, maxFieldLength: maxFieldLength, lastEvent: &lastEvent}
+		if err := output.Pager(c, iter, opts); err != nil {
 			errChan <- err
 			return
 		}
@@ -341,7 +384,7 @@ func printWorkflowProgress(c *cli.Context, wid, rid string, watch bool) error {
 
 	for {
 		select {
-		case <-ticker:
+		case <-ticker.C:
 			if !watch {
 				continue
 			}
@@ -349,18 +392,12 @@ func printWorkflowProgress(c *cli.Context, wid, rid string, watch bool) error {
 			if isTimeElapseExist {
 				removePrevious2LinesFromTerminal()
 			}
-			fmt.Printf("\nTime elapse: %ds\n", timeElapse)
+			fmt.Printf("\nTime elapse: %ds\n", int(time.Since(startTime).Seconds()))
 			isTimeElapseExist = true
-			timeElapse++
-		case <-doneChan: // print result of this run
-			fmt.Println(color.Magenta(c, "\nResult:"))
-			if watch {
-				fmt.Printf("  Run Time: %d seconds\n", timeElapse)
-			}
-			printRunStatus(c, &lastEvent)
-			return nil
-		case err = <-errChan:
-			return err
+		case <-doneChan:
+			return &lastEvent, nil
+		case err := <-errChan:
+			return nil, err
 		}
 	}
 }
@@ -375,6 +412,14 @@ func TerminateWorkflow(c *cli.Context) error {
 	wid := c.String(FlagWorkflowID)
 	rid := c.String(FlagRunID)
 	reason := c.String(FlagReason)
+	inFileName := c.String(FlagInputFile)
+
+	if inFileName != "" {
+		return terminateFromFile(c, sdkClient, inFileName, reason)
+	}
+	if wid == "" {
+		return fmt.Errorf("must provide workflow Id or input file of the workflows to terminate")
+	}
 
 	ctx, cancel := newContext(c)
 	defer cancel()
@@ -388,6 +433,86 @@ func TerminateWorkflow(c *cli.Context) error {
 	return nil
 }
 
+// terminateFromFile terminates the workflows listed in the input file after confirmation, it keeps going when a
+// workflow fails to terminate
+func terminateFromFile(c *cli.Context, sdkClient sdkclient.Client, inFileName, reason string) error {
+	rps := c.Int(FlagRPS)
+	if rps < 1 {
+		return fmt.Errorf("--%s must be at least 1", FlagRPS)
+	}
+
+	// This code is only used in the CLI. The input provided is from a trusted user.
+	// #nosec
+	inFile, err := os.Open(inFileName)
+	if err != nil {
+		return fmt.Errorf("unable to open input file: %s", err)
+	}
+	defer inFile.Close()
+
+	var executions []*commonpb.WorkflowExecution
+	scanner := bufio.NewScanner(inFile)
+	idx := 0
+	for scanner.Scan() {
+		idx++
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 {
+			continue
+		}
+		wid, rid, err := parseExecutionLine(line, "\t")
+		if err != nil {
+			return fmt.Errorf("input file: line %v: %s", idx, err)
+		}
+		executions = append(executions, &commonpb.WorkflowExecution{WorkflowId: wid, RunId: rid})
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("unable to read input file: %s", err)
+	}
+
+	if c.Bool(FlagDryRun) {
+		for _, execution := range executions {
+			fmt.Printf("%s\t%s\n", execution.GetWorkflowId(), execution.GetRunId())
+		}
+		fmt.Printf("Would terminate %d workflows\n", len(executions))
+		return nil
+	}
+	fmt.Printf("This will terminate %d workflows, with max RPS of %d.\n", len(executions), rps)
+	if !c.Bool(FlagYes) {
+		confirmed, err := promptYes()
+		if err != nil {
+			return fmt.Errorf("failed to get confirmation to terminate the workflows: %s", err)
+		}
+		if !confirmed {
+			fmt.Println("Workflows are not terminated")
+			return nil
+		}
+	}
+
+	ctx, cancel := newIndefiniteContext(c)
+	defer cancel()
+	limiter := rate.NewLimiter(rate.Limit(rps), 1)
+	terminated, failed := 0, 0
+	for _, execution := range executions {
+		if err := limiter.Wait(ctx); err != nil {
+			return err
+		}
+		rpcCtx, cancel := newContext(c)
+		err = sdkClient.TerminateWorkflow(rpcCtx, execution.GetWorkflowId(), execution.GetRunId(), reason, nil)
+		cancel()
+		if err != nil {
+			failed++
+			fmt.Printf("unable to terminate workflow %s: %s\n", execution.GetWorkflowId(), err)
+			continue
+		}
+		terminated++
+	}
+
+	fmt.Printf("Terminated %d workflows\n", terminated)
+	if failed > 0 {
+		return fmt.Errorf("failed to terminate %d workflows", failed)
+	}
+	return nil
+}
+
 // CancelWorkflow cancels a workflow execution
 func CancelWorkflow(c *cli.Context) error {
 	sdkClient, err := getSDKClient(c)
@@ -411,7 +536,10 @@ func CancelWorkflow(c *cli.Context) error {
 
 // SignalWorkflow signals a workflow execution
 func SignalWorkflow(c *cli.Context) error {
-	serviceClient := cFactory.FrontendClient(c)
+	serviceClient, err := cFactory.FrontendClient(c)
+	if err != nil {
+		return err
+	}
 
 	namespace, err := getRequiredGlobalOption(c, FlagNamespace)
 	if err != nil {
@@ -450,6 +578,10 @@ func SignalWorkflow(c *cli.Context) error {
 
 // QueryWorkflow query workflow execution
 func QueryWorkflow(c *cli.Context) error {
+	if !c.IsSet(FlagQueryType) {
+		return listQueryTypes(c)
+	}
+
 	queryType := c.String(FlagQueryType)
 
 	if err := queryWorkflowHelper(c, queryType); err != nil {
@@ -459,33 +591,95 @@ func QueryWorkflow(c *cli.Context) error {
 	return nil
 }
 
-// QueryWorkflowUsingStackTrace query workflow execution using __stack_trace as query type
+// QueryWorkflowUsingStackTrace query workflow execution using __stack_trace as query type.
+// With a visibility query, the stack traces of all matching workflows are grouped.
 func QueryWorkflowUsingStackTrace(c *cli.Context) error {
-	return queryWorkflowHelper(c, "__stack_trace")
+	if c.IsSet(FlagListQuery) {
+		if c.IsSet(FlagWorkflowID) {
+			return fmt.Errorf("provide either --%s or --%s, not both", FlagWorkflowID, FlagListQuery)
+		}
+		return aggregateStackTraces(c)
+	}
+	if !c.IsSet(FlagWorkflowID) {
+		return fmt.Errorf("either --%s or --%s is required", FlagWorkflowID, FlagListQuery)
+	}
+
+	return queryWorkflowHelper(c, stackTraceQueryType)
 }
 
-func queryWorkflowHelper(c *cli.Context, queryType string) error {
-	serviceClient := cFactory.FrontendClient(c)
+// queryResult is the JSON output of a query
+type queryResult struct {
+	QueryType string      `json:"queryType"`
+	Result    interface{} `json:"result,omitempty"`
+	// RejectedStatus is the status of the workflow if the query was rejected
+	RejectedStatus string `json:"rejectedStatus,omitempty"`
+}
 
-	namespace, err := getRequiredGlobalOption(c, FlagNamespace)
+func queryWorkflowHelper(c *cli.Context, queryType string) error {
+	queryResponse, err := queryWorkflow(c, queryType)
 	if err != nil {
 		return err
 	}
-	wid := c.String(FlagWorkflowID)
-	rid := c.String(FlagRunID)
-	input, err := processJSONInput(c)
+
+	res := queryResult{QueryType: queryType}
+	if queryResponse.QueryRejected != nil {
+		res.RejectedStatus = queryResponse.QueryRejected.GetStatus().String()
+	} else {
+		res.Result = decodePayloads(queryResponse.QueryResult)
+	}
+
+	if c.String(output.FlagOutput) == string(output.JSON) {
+		output.PrintJSON(c, res, &output.PrintOptions{Pager: os.Stdout})
+		return nil
+	}
+
+	if queryResponse.QueryRejected != nil {
+		fmt.Printf("Query was rejected, workflow has status: %v\n", res.RejectedStatus)
+	} else {
+		fmt.Printf("Query result:\n%v\n", formatDecodedPayloads(res.Result))
+	}
+
+	return nil
+}
+
+func queryWorkflow(c *cli.Context, queryType string) (*workflowservice.QueryWorkflowResponse, error) {
+	serviceClient, err := cFactory.FrontendClient(c)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	queryRequest, err := newQueryRequest(c, queryType)
+	if err != nil {
+		return nil, err
+	}
+	queryRequest.Execution = &commonpb.WorkflowExecution{
+		WorkflowId: c.String(FlagWorkflowID),
+		RunId:      c.String(FlagRunID),
 	}
 
 	tcCtx, cancel := newContext(c)
 	defer cancel()
+	queryResponse, err := serviceClient.QueryWorkflow(tcCtx, queryRequest)
+	if err != nil {
+		return nil, fmt.Errorf("query workflow failed: %s", err)
+	}
+
+	return queryResponse, nil
+}
+
+// newQueryRequest creates a query request from the flags, without the execution to query
+func newQueryRequest(c *cli.Context, queryType string) (*workflowservice.QueryWorkflowRequest, error) {
+	namespace, err := getRequiredGlobalOption(c, FlagNamespace)
+	if err != nil {
+		return nil, err
+	}
+	input, err := processJSONInput(c)
+	if err != nil {
+		return nil, err
+	}
+
 	queryRequest := &workflowservice.QueryWorkflowRequest{
 		Namespace: namespace,
-		Execution: &commonpb.WorkflowExecution{
-			WorkflowId: wid,
-			RunId:      rid,
-		},
 		Query: &querypb.WorkflowQuery{
 			QueryType: queryType,
 		},
@@ -501,25 +695,104 @@ func queryWorkflowHelper(c *cli.Context, queryType string) error {
 		case "not_completed_cleanly":
 			rejectCondition = enumspb.QUERY_REJECT_CONDITION_NOT_COMPLETED_CLEANLY
 		default:
-			return fmt.Errorf("invalid reject condition %v, valid values are \"not_open\" and \"not_completed_cleanly\"", c.String(FlagQueryRejectCondition))
+			return nil, fmt.Errorf("invalid reject condition %v, valid values are \"not_open\" and \"not_completed_cleanly\"", c.String(FlagQueryRejectCondition))
 		}
 		queryRequest.QueryRejectCondition = rejectCondition
 	}
-	queryResponse, err := serviceClient.QueryWorkflow(tcCtx, queryRequest)
+
+	return queryRequest, nil
+}
+
+// listQueryTypes prints the query types registered by the workflow. Only SDKs that
+// support the __temporal_workflow_metadata query report them.
+func listQueryTypes(c *cli.Context) error {
+	queryResponse, err := queryWorkflow(c, workflowMetadataQueryType)
 	if err != nil {
-		return fmt.Errorf("query workflow failed: %s", err)
+		return fmt.Errorf("unable to list query types, the workflow may not support %s. Specify the query type with --%s.\n%s", workflowMetadataQueryType, FlagQueryType, err)
 	}
-
 	if queryResponse.QueryRejected != nil {
-		fmt.Printf("Query was rejected, workflow has status: %v\n", queryResponse.QueryRejected.GetStatus())
-	} else {
-		queryResult := stringify.AnyToString(queryResponse.QueryResult, true, 0, customDataConverter())
-		fmt.Printf("Query result:\n%v\n", queryResult)
+		return fmt.Errorf("query was rejected, workflow has status: %v", queryResponse.QueryRejected.GetStatus())
+	}
+
+	var metadata struct {
+		Definition struct {
+			QueryDefinitions []struct {
+				Name        string `json:"name"`
+				Description string `json:"description"`
+			} `json:"queryDefinitions"`
+		} `json:"definition"`
+	}
+	decoded, err := json.Marshal(decodePayloads(queryResponse.QueryResult))
+	if err != nil {
+		return fmt.Errorf("unable to decode workflow metadata: %s", err)
+	}
+	if err := json.Unmarshal(decoded, &metadata); err != nil {
+		return fmt.Errorf("unable to decode workflow metadata: %s", err)
+	}
+
+	type queryTypeItem struct {
+		Name        string
+		Description string
 	}
+	var items []interface{}
+	for _, def := range metadata.Definition.QueryDefinitions {
+		items = append(items, queryTypeItem{Name: def.Name, Description: def.Description})
+	}
+	output.PrintItems(c, items, &output.PrintOptions{
+		Fields: []string{"Name", "Description"},
+	})
 
 	return nil
 }
 
+// decodePayloads decodes payloads with the data converter in use. A single payload is
+// returned as is, multiple payloads are returned as a slice.
+func decodePayloads(p *commonpb.Payloads) interface{} {
+	var values []interface{}
+	for _, payload := range p.GetPayloads() {
+		values = append(values, decodePayload(payload))
+	}
+
+	switch len(values) {
+	case 0:
+		return nil
+	case 1:
+		return values[0]
+	default:
+		return values
+	}
+}
+
+func decodePayload(payload *commonpb.Payload) interface{} {
+	dc := customDataConverter()
+
+	var value interface{}
+	if err := dc.FromPayload(payload, &value); err == nil {
+		return value
+	}
+	// proto messages can only be decoded into their Go type, which tctl doesn't know
+	if string(payload.GetMetadata()[converter.MetadataEncoding]) == converter.MetadataEncodingProtoJSON {
+		if err := json.Unmarshal(payload.GetData(), &value); err == nil {
+			return value
+		}
+	}
+
+	return dc.ToString(payload)
+}
+
+// formatDecodedPayloads formats decoded payloads for humans, strings such as stack traces are printed as is
+func formatDecodedPayloads(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+
+	b, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(b)
+}
+
 // ListWorkflow list workflow executions based on filters
 func ListWorkflow(c *cli.Context) error {
 	namespace, err := getRequiredGlobalOption(c, FlagNamespace)
@@ -714,10 +987,17 @@ func ListArchivedWorkflow(c *cli.Context) error {
 
 // DescribeWorkflow show information about the specified workflow execution
 func DescribeWorkflow(c *cli.Context) error {
+	if c.IsSet(FlagAtEvent) {
+		return DescribeWorkflowAtEvent(c)
+	}
+
 	wid := c.String(FlagWorkflowID)
 	rid := c.String(FlagRunID)
 
-	frontendClient := cFactory.FrontendClient(c)
+	frontendClient, err := cFactory.FrontendClient(c)
+	if err != nil {
+		return err
+	}
 	namespace, err := getRequiredGlobalOption(c, FlagNamespace)
 	if err != nil {
 		return err
@@ -747,10 +1027,53 @@ func DescribeWorkflow(c *cli.Context) error {
 
 	if printRaw {
 		prettyPrintJSONObject(resp)
-	} else {
-		prettyPrintJSONObject(convertDescribeWorkflowExecutionResponse(c, resp))
+		return nil
 	}
 
+	return printWorkflowDescription(c, resp)
+}
+
+// workflowDescription is the output of describe: the fields of the converted response, followed by the cause chains
+// of the last failures of the pending activities with their details decoded
+type workflowDescription struct {
+	ExecutionConfig         json.RawMessage          `json:"executionConfig,omitempty"`
+	WorkflowExecutionInfo   json.RawMessage          `json:"workflowExecutionInfo,omitempty"`
+	PendingActivities       json.RawMessage          `json:"pendingActivities,omitempty"`
+	PendingChildren         json.RawMessage          `json:"pendingChildren,omitempty"`
+	PendingWorkflowTask     json.RawMessage          `json:"pendingWorkflowTask,omitempty"`
+	PendingActivityFailures []pendingActivityFailure `json:"pendingActivityFailures,omitempty"`
+}
+
+type pendingActivityFailure struct {
+	ActivityID string `json:"activityId"`
+	// LastFailure is the cause chain of the last failure, outermost first
+	LastFailure []failureLevel `json:"lastFailure"`
+}
+
+func printWorkflowDescription(c *cli.Context, resp *workflowservice.DescribeWorkflowExecutionResponse) error {
+	converted, err := codec.NewJSONPBEncoder().Encode(convertDescribeWorkflowExecutionResponse(c, resp))
+	if err != nil {
+		return fmt.Errorf("unable to encode workflow description: %s", err)
+	}
+	var description workflowDescription
+	if err := json.Unmarshal(converted, &description); err != nil {
+		return fmt.Errorf("unable to encode workflow description: %s", err)
+	}
+	for _, activity := range resp.GetPendingActivities() {
+		if activity.GetLastFailure() != nil {
+			description.PendingActivityFailures = append(description.PendingActivityFailures, pendingActivityFailure{
+				ActivityID:  activity.GetActivityId(),
+				LastFailure: failureLevels(activity.GetLastFailure()),
+			})
+		}
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(description); err != nil {
+		return fmt.Errorf("unable to encode workflow description: %s", err)
+	}
 	return nil
 }
 
@@ -845,7 +1168,10 @@ func convertFailure(failure *failurepb.Failure) *clispb.Failure {
 		return nil
 	}
 
-	fType := reflect.TypeOf(failure.GetFailureInfo()).Elem().Name()
+	var fType string
+	if failure.GetFailureInfo() != nil {
+		fType = reflect.TypeOf(failure.GetFailureInfo()).Elem().Name()
+	}
 	if failure.GetTimeoutFailureInfo() != nil {
 		fType = fmt.Sprintf("%s: %s", fType, failure.GetTimeoutFailureInfo().GetTimeoutType().String())
 	}
@@ -869,7 +1195,7 @@ func printRunStatus(c *cli.Context, event *historypb.HistoryEvent) {
 		fmt.Printf("  Output: %s\n", result)
 	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_FAILED:
 		fmt.Printf("  Status: %s\n", color.Red(c, "FAILED"))
-		fmt.Printf("  Failure: %s\n", convertFailure(event.GetWorkflowExecutionFailedEventAttributes().GetFailure()).String())
+		fmt.Printf("  Failure:\n%s\n", formatFailure(c, event.GetWorkflowExecutionFailedEventAttributes().GetFailure(), "    "))
 	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_TIMED_OUT:
 		fmt.Printf("  Status: %s\n", color.Red(c, "TIMEOUT"))
 		fmt.Printf("  Retry status: %s\n", event.GetWorkflowExecutionTimedOutEventAttributes().GetRetryState())
@@ -877,6 +1203,12 @@ func printRunStatus(c *cli.Context, event *historypb.HistoryEvent) {
 		fmt.Printf("  Status: %s\n", color.Red(c, "CANCELED"))
 		details := stringify.AnyToString(event.GetWorkflowExecutionCanceledEventAttributes().GetDetails(), true, 0, customDataConverter())
 		fmt.Printf("  Detail: %s\n", details)
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_TERMINATED:
+		fmt.Printf("  Status: %s\n", color.Red(c, "TERMINATED"))
+		fmt.Printf("  Reason: %s\n", event.GetWorkflowExecutionTerminatedEventAttributes().GetReason())
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_CONTINUED_AS_NEW:
+		fmt.Printf("  Status: %s\n", color.Yellow(c, "CONTINUED_AS_NEW"))
+		fmt.Printf("  New run ID: %s\n", event.GetWorkflowExecutionContinuedAsNewEventAttributes().GetNewExecutionRunId())
 	}
 }
 
@@ -948,7 +1280,10 @@ func ResetWorkflow(c *cli.Context) error {
 	ctx, cancel := newContext(c)
 	defer cancel()
 
-	frontendClient := cFactory.FrontendClient(c)
+	frontendClient, err := cFactory.FrontendClient(c)
+	if err != nil {
+		return err
+	}
 
 	resetBaseRunID := rid
 	workflowTaskFinishID := eventID
@@ -1080,11 +1415,10 @@ func ResetInBatch(c *cli.Context) error {
 				fmt.Printf("line %v is empty, skipped\n", idx)
 				continue
 			}
-			cols := strings.Split(line, separator)
-			if len(cols) < 1 {
-				return fmt.Errorf("exclude file: unable to split, line %v has less than 1 cols separated by comma, only %v", idx, len(cols))
+			wid, _, err := parseExecutionLine(line, separator)
+			if err != nil {
+				return fmt.Errorf("exclude file: line %v: %s", idx, err)
 			}
-			wid := strings.TrimSpace(cols[0])
 			rid := "not-needed"
 			excludes[wid] = rid
 		}
@@ -1106,16 +1440,11 @@ func ResetInBatch(c *cli.Context) error {
 				fmt.Printf("line %v is empty, skipped\n", idx)
 				continue
 			}
-			cols := strings.Split(line, separator)
-			if len(cols) < 1 {
-				return fmt.Errorf("include file: unable to split, line %v has less than 1 cols separated by comma, only %v", idx, len(cols))
+			wid, rid, err := parseExecutionLine(line, separator)
+			if err != nil {
+				return fmt.Errorf("include file: line %v: %s", idx, err)
 			}
 			fmt.Printf("Start processing line %v ...\n", idx)
-			wid := strings.TrimSpace(cols[0])
-			rid := ""
-			if len(cols) > 1 {
-				rid = strings.TrimSpace(cols[1])
-			}
 
 			_, ok := excludes[wid]
 			if ok {
@@ -1170,6 +1499,32 @@ func ResetInBatch(c *cli.Context) error {
 	return nil
 }
 
+// parseExecutionLine reads a workflow from a line of an input file. The line is either the workflow Id and optionally
+// the run Id separated by separator, or a JSON object with workflowId and runId fields like the lines of "workflow find-stuck"
+func parseExecutionLine(line, separator string) (string, string, error) {
+	if strings.HasPrefix(line, "{") {
+		var execution struct {
+			WorkflowID string `json:"workflowId"`
+			RunID      string `json:"runId"`
+		}
+		if err := json.Unmarshal([]byte(line), &execution); err != nil {
+			return "", "", fmt.Errorf("invalid JSON: %s", err)
+		}
+		if execution.WorkflowID == "" {
+			return "", "", fmt.Errorf("workflowId is missing")
+		}
+		return execution.WorkflowID, execution.RunID, nil
+	}
+
+	cols := strings.Split(line, separator)
+	wid := strings.TrimSpace(cols[0])
+	rid := ""
+	if len(cols) > 1 {
+		rid = strings.TrimSpace(cols[1])
+	}
+	return wid, rid, nil
+}
+
 func printErrorAndReturn(msg string, err error) error {
 	fmt.Println(msg)
 	return err
@@ -1179,7 +1534,10 @@ func doReset(c *cli.Context, namespace, wid, rid string, params batchResetParams
 	ctx, cancel := newContext(c)
 	defer cancel()
 
-	frontendClient := cFactory.FrontendClient(c)
+	frontendClient, err := cFactory.FrontendClient(c)
+	if err != nil {
+		return err
+	}
 	resp, err := frontendClient.DescribeWorkflowExecution(ctx, &workflowservice.DescribeWorkflowExecutionRequest{
 		Namespace: namespace,
 		Execution: &commonpb.WorkflowExecution{
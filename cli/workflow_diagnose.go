@@ -0,0 +1,441 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/temporalio/tctl-kit/pkg/color"
+	"github.com/temporalio/tctl-kit/pkg/output"
+	"github.com/urfave/cli/v2"
+	enumspb "go.temporal.io/api/enums/v1"
+	failurepb "go.temporal.io/api/failure/v1"
+	historypb "go.temporal.io/api/history/v1"
+	taskqueuepb "go.temporal.io/api/taskqueue/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/server/common/primitives/timestamp"
+)
+
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+
+	// tasks are normally started within milliseconds, a task that has waited longer than this is considered stuck
+	stuckTaskThreshold = time.Minute
+)
+
+// diagnosisFinding is a known pathology found in a workflow execution
+type diagnosisFinding struct {
+	Severity    string   `json:"severity"`
+	Problem     string   `json:"problem"`
+	Details     []string `json:"details,omitempty"`
+	Remediation string   `json:"remediation"`
+}
+
+type taskQueueKey struct {
+	Name string
+	Type enumspb.TaskQueueType
+}
+
+// taskQueuePollers counts the pollers of a task queue, live ones have polled recently
+type taskQueuePollers struct {
+	Live  int
+	Total int
+}
+
+// diagnosisInput is everything the checks look at
+type diagnosisInput struct {
+	Namespace string
+	Describe  *workflowservice.DescribeWorkflowExecutionResponse
+	Events    []*historypb.HistoryEvent
+	// Pollers of the task queues of tasks waiting to be started
+	Pollers map[taskQueueKey]taskQueuePollers
+	Now     time.Time
+}
+
+// DiagnoseWorkflow inspects a workflow execution and reports known pathologies along with how to remediate them
+func DiagnoseWorkflow(c *cli.Context) error {
+	namespace, err := getRequiredGlobalOption(c, FlagNamespace)
+	if err != nil {
+		return err
+	}
+	sdkClient, err := getSDKClient(c)
+	if err != nil {
+		return err
+	}
+	frontendClient, err := cFactory.FrontendClient(c)
+	if err != nil {
+		return err
+	}
+	wid := c.String(FlagWorkflowID)
+	rid := c.String(FlagRunID)
+
+	ctx, cancel := newIndefiniteContext(c)
+	defer cancel()
+
+	resp, err := sdkClient.DescribeWorkflowExecution(ctx, wid, rid)
+	if err != nil {
+		return fmt.Errorf("workflow describe failed: %s", err)
+	}
+	rid = resp.GetWorkflowExecutionInfo().GetExecution().GetRunId()
+	events, err := getHistoryEvents(ctx, sdkClient, wid, rid)
+	if err != nil {
+		return fmt.Errorf("unable to read workflow history: %s", err)
+	}
+
+	input := &diagnosisInput{
+		Namespace: namespace,
+		Describe:  resp,
+		Events:    events,
+		Pollers:   make(map[taskQueueKey]taskQueuePollers),
+		Now:       time.Now(),
+	}
+	for _, key := range waitingTaskQueues(input) {
+		tqResp, err := frontendClient.DescribeTaskQueue(ctx, &workflowservice.DescribeTaskQueueRequest{
+			Namespace:     namespace,
+			TaskQueue:     &taskqueuepb.TaskQueue{Name: key.Name, Kind: enumspb.TASK_QUEUE_KIND_NORMAL},
+			TaskQueueType: key.Type,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to describe task queue %s: %s", key.Name, err)
+		}
+		pollers := taskQueuePollers{Total: len(tqResp.GetPollers())}
+		for _, poller := range tqResp.GetPollers() {
+			if !isStalePoller(poller, input.Now, defaultStalePollerSeconds*time.Second) {
+				pollers.Live++
+			}
+		}
+		input.Pollers[key] = pollers
+	}
+
+	findings := diagnose(input, wid, rid)
+
+	if c.String(output.FlagOutput) == string(output.JSON) {
+		output.PrintJSON(c, findings, &output.PrintOptions{Pager: os.Stdout})
+		return nil
+	}
+
+	fmt.Printf("Workflow %s is %s\n", wid, resp.GetWorkflowExecutionInfo().GetStatus())
+	if len(findings) == 0 {
+		fmt.Println("No known problems found")
+		return nil
+	}
+	for _, finding := range findings {
+		severity := color.Yellow(c, "%s", strings.ToUpper(finding.Severity))
+		if finding.Severity == severityError {
+			severity = color.Red(c, "%s", strings.ToUpper(finding.Severity))
+		}
+		fmt.Printf("\n%s %s\n", severity, finding.Problem)
+		for _, detail := range finding.Details {
+			fmt.Printf("  %s\n", detail)
+		}
+		fmt.Printf("  %s %s\n", color.Magenta(c, "Remediation:"), finding.Remediation)
+	}
+	return nil
+}
+
+// diagnose runs all checks, errors are reported first
+func diagnose(input *diagnosisInput, wid, rid string) []diagnosisFinding {
+	var findings []diagnosisFinding
+	findings = append(findings, checkWorkflowTaskFailures(input, wid, rid)...)
+	findings = append(findings, checkWorkflowTaskNotStarted(input)...)
+	findings = append(findings, checkActivities(input)...)
+	findings = append(findings, checkHeartbeatTimeouts(input)...)
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Severity == severityError && findings[j].Severity != severityError
+	})
+	return findings
+}
+
+// waitingTaskQueues returns the task queues of the tasks that have waited too long to be started
+func waitingTaskQueues(input *diagnosisInput) []taskQueueKey {
+	var keys []taskQueueKey
+	seen := make(map[taskQueueKey]bool)
+	add := func(key taskQueueKey) {
+		if key.Name != "" && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	if isWaitingWorkflowTask(input) {
+		add(taskQueueKey{Name: input.Describe.GetExecutionConfig().GetTaskQueue().GetName(), Type: enumspb.TASK_QUEUE_TYPE_WORKFLOW})
+	}
+	activityTaskQueues := scheduledActivityTaskQueues(input.Events)
+	for _, activity := range input.Describe.GetPendingActivities() {
+		if isWaitingActivity(input, activity.GetState(), activity.GetScheduledTime()) {
+			add(taskQueueKey{Name: activityTaskQueues[activity.GetActivityId()], Type: enumspb.TASK_QUEUE_TYPE_ACTIVITY})
+		}
+	}
+	return keys
+}
+
+func isWaitingWorkflowTask(input *diagnosisInput) bool {
+	task := input.Describe.GetPendingWorkflowTask()
+	return task.GetState() == enumspb.PENDING_WORKFLOW_TASK_STATE_SCHEDULED &&
+		input.Now.Sub(timestamp.TimeValue(task.GetScheduledTime())) > stuckTaskThreshold
+}
+
+func isWaitingActivity(input *diagnosisInput, state enumspb.PendingActivityState, scheduledTime *time.Time) bool {
+	return state == enumspb.PENDING_ACTIVITY_STATE_SCHEDULED && scheduledTime != nil &&
+		input.Now.Sub(*scheduledTime) > stuckTaskThreshold
+}
+
+// scheduledActivityTaskQueues maps activity Ids to the task queues they were scheduled on
+func scheduledActivityTaskQueues(events []*historypb.HistoryEvent) map[string]string {
+	taskQueues := make(map[string]string)
+	for _, event := range events {
+		if attr := event.GetActivityTaskScheduledEventAttributes(); attr != nil {
+			taskQueues[attr.GetActivityId()] = attr.GetTaskQueue().GetName()
+		}
+	}
+	return taskQueues
+}
+
+func resetCommand(namespace, wid, rid, resetType string, extra string) string {
+	cmd := fmt.Sprintf("tctl --namespace %s workflow reset --workflow-id %s --run-id %s --reset-type %s", namespace, wid, rid, resetType)
+	if extra != "" {
+		cmd += " " + extra
+	}
+	return cmd + ` --reason "<reason>"`
+}
+
+// checkWorkflowTaskFailures reports a workflow task that keeps failing. Only the first failure of a series is
+// written to the history, the number of attempts comes from the pending workflow task.
+func checkWorkflowTaskFailures(input *diagnosisInput, wid, rid string) []diagnosisFinding {
+	var failed *historypb.HistoryEvent
+	var binaries []string
+	for _, event := range input.Events {
+		switch event.GetEventType() {
+		case enumspb.EVENT_TYPE_WORKFLOW_TASK_FAILED:
+			failed = event
+		case enumspb.EVENT_TYPE_WORKFLOW_TASK_COMPLETED:
+			failed = nil
+			checksum := event.GetWorkflowTaskCompletedEventAttributes().GetBinaryChecksum()
+			if checksum != "" && (len(binaries) == 0 || binaries[len(binaries)-1] != checksum) {
+				binaries = append(binaries, checksum)
+			}
+		}
+	}
+	if failed == nil {
+		return nil
+	}
+
+	attr := failed.GetWorkflowTaskFailedEventAttributes()
+	details := []string{fmt.Sprintf("First failure at event %d: %s", failed.GetEventId(), attr.GetFailure().GetMessage())}
+	if attempt := input.Describe.GetPendingWorkflowTask().GetAttempt(); attempt > 1 {
+		details = append(details, fmt.Sprintf("The workflow task has been attempted %d times", attempt))
+	}
+	if identity := attr.GetIdentity(); identity != "" {
+		details = append(details, "Last failing worker: "+identity)
+	}
+
+	switch {
+	case attr.GetCause() == enumspb.WORKFLOW_TASK_FAILED_CAUSE_NON_DETERMINISTIC_ERROR ||
+		strings.Contains(attr.GetFailure().GetMessage(), "nondeterministic"):
+		// the newest deployment is the likely culprit
+		checksum := "<checksum>"
+		if len(binaries) > 0 {
+			details = append(details, "Worker binaries that completed workflow tasks, oldest first: "+strings.Join(binaries, ", "))
+			checksum = binaries[len(binaries)-1]
+		}
+		return []diagnosisFinding{{
+			Severity: severityError,
+			Problem:  "Workflow task fails with a non-determinism error",
+			Details:  details,
+			Remediation: "Deploy workflow code that is compatible with the history, using versioning for changes to the workflow logic; " +
+				"the workflow task is retried automatically. If an incompatible deployment already completed workflow tasks, reset to before it: " +
+				resetCommand(input.Namespace, wid, rid, "BadBinary", "--reset-bad-binary-checksum "+checksum),
+		}}
+	case attr.GetCause() == enumspb.WORKFLOW_TASK_FAILED_CAUSE_BAD_BINARY:
+		return []diagnosisFinding{{
+			Severity:    severityError,
+			Problem:     fmt.Sprintf("Workflow task fails because worker binary %s is marked as bad", attr.GetBinaryChecksum()),
+			Details:     details,
+			Remediation: "Reset to before the first workflow task completed by the bad binary: " + resetCommand(input.Namespace, wid, rid, "BadBinary", "--reset-bad-binary-checksum "+attr.GetBinaryChecksum()),
+		}}
+	default:
+		return []diagnosisFinding{{
+			Severity: severityError,
+			Problem:  fmt.Sprintf("Workflow task fails with cause %s", attr.GetCause()),
+			Details:  details,
+			Remediation: "Fix the error in the workflow code, the stack trace is in the failure of the event; the workflow task is retried automatically. " +
+				"To discard the progress made since the last successful workflow task: " + resetCommand(input.Namespace, wid, rid, "LastWorkflowTask", ""),
+		}}
+	}
+}
+
+func noPollersFinding(input *diagnosisInput, what string, scheduledTime time.Time, key taskQueueKey) (diagnosisFinding, bool) {
+	pollers, ok := input.Pollers[key]
+	if !ok {
+		return diagnosisFinding{}, false
+	}
+
+	waiting := roundDuration(input.Now.Sub(scheduledTime))
+	if pollers.Live == 0 {
+		details := []string{fmt.Sprintf("No worker has polled %s task queue %s recently", strings.ToLower(key.Type.String()), key.Name)}
+		if pollers.Total > 0 {
+			details = append(details, fmt.Sprintf("%d pollers haven't polled for more than %ds", pollers.Total, defaultStalePollerSeconds))
+		}
+		return diagnosisFinding{
+			Severity: severityError,
+			Problem:  fmt.Sprintf("%s was scheduled %s ago and never started", what, waiting),
+			Details:  details,
+			Remediation: fmt.Sprintf("Start workers polling task queue %s in namespace %s, or check the task queue and namespace the workers are configured with",
+				key.Name, input.Namespace),
+		}, true
+	}
+
+	return diagnosisFinding{
+		Severity:    severityWarning,
+		Problem:     fmt.Sprintf("%s was scheduled %s ago and hasn't started", what, waiting),
+		Details:     []string{fmt.Sprintf("%d workers poll %s task queue %s", pollers.Live, strings.ToLower(key.Type.String()), key.Name)},
+		Remediation: "The workers may be overloaded: check their logs, resource usage and limits on concurrent task executions",
+	}, true
+}
+
+// checkWorkflowTaskNotStarted reports a workflow task that no worker picks up
+func checkWorkflowTaskNotStarted(input *diagnosisInput) []diagnosisFinding {
+	if !isWaitingWorkflowTask(input) {
+		return nil
+	}
+
+	key := taskQueueKey{Name: input.Describe.GetExecutionConfig().GetTaskQueue().GetName(), Type: enumspb.TASK_QUEUE_TYPE_WORKFLOW}
+	scheduled := timestamp.TimeValue(input.Describe.GetPendingWorkflowTask().GetScheduledTime())
+	if finding, ok := noPollersFinding(input, "Workflow task", scheduled, key); ok {
+		return []diagnosisFinding{finding}
+	}
+	return nil
+}
+
+// checkActivities reports activities that are retrying, or that no worker picks up
+func checkActivities(input *diagnosisInput) []diagnosisFinding {
+	var findings []diagnosisFinding
+	activityTaskQueues := scheduledActivityTaskQueues(input.Events)
+	for _, activity := range input.Describe.GetPendingActivities() {
+		name := fmt.Sprintf("Activity %s (%s)", activity.GetActivityId(), activity.GetActivityType().GetName())
+
+		if isWaitingActivity(input, activity.GetState(), activity.GetScheduledTime()) {
+			key := taskQueueKey{Name: activityTaskQueues[activity.GetActivityId()], Type: enumspb.TASK_QUEUE_TYPE_ACTIVITY}
+			if finding, ok := noPollersFinding(input, name, *activity.GetScheduledTime(), key); ok {
+				findings = append(findings, finding)
+			}
+		}
+
+		// heartbeat timeouts are reported by checkHeartbeatTimeouts
+		if activity.GetAttempt() <= 1 || isHeartbeatTimeout(activity.GetLastFailure()) {
+			continue
+		}
+		attempts := fmt.Sprintf("attempt %d", activity.GetAttempt())
+		retryPolicy := "its retry policy"
+		if activity.GetMaximumAttempts() > 0 {
+			attempts += fmt.Sprintf(" of %d", activity.GetMaximumAttempts())
+		} else {
+			retryPolicy += ", which has no maximum attempts"
+		}
+		var details []string
+		if levels := failureLevels(activity.GetLastFailure()); len(levels) > 0 {
+			details = append(details, fmt.Sprintf("Last failure: %s: %s", levels[len(levels)-1].Type, levels[len(levels)-1].Message))
+		}
+		if identity := activity.GetLastWorkerIdentity(); identity != "" {
+			details = append(details, "Last worker: "+identity)
+		}
+		findings = append(findings, diagnosisFinding{
+			Severity: severityWarning,
+			Problem:  fmt.Sprintf("%s is retrying, %s", name, attempts),
+			Details:  details,
+			Remediation: fmt.Sprintf("Fix the cause of the failure, the activity is retried according to %s. "+
+				"Errors that can't succeed on retry should be returned as non-retryable application errors", retryPolicy),
+		})
+	}
+	return findings
+}
+
+func isHeartbeatTimeout(failure *failurepb.Failure) bool {
+	for f := failure; f != nil; f = f.GetCause() {
+		if f.GetTimeoutFailureInfo().GetTimeoutType() == enumspb.TIMEOUT_TYPE_HEARTBEAT {
+			return true
+		}
+	}
+	return false
+}
+
+// checkHeartbeatTimeouts reports activity types whose activities time out on heartbeat
+func checkHeartbeatTimeouts(input *diagnosisInput) []diagnosisFinding {
+	type activityTimeouts struct {
+		count            int
+		heartbeatTimeout time.Duration
+	}
+	scheduled := make(map[int64]*historypb.ActivityTaskScheduledEventAttributes)
+	byType := make(map[string]*activityTimeouts)
+	var types []string
+	add := func(activityType string, heartbeatTimeout time.Duration) {
+		timeouts, ok := byType[activityType]
+		if !ok {
+			timeouts = &activityTimeouts{}
+			byType[activityType] = timeouts
+			types = append(types, activityType)
+		}
+		timeouts.count++
+		if heartbeatTimeout > 0 {
+			timeouts.heartbeatTimeout = heartbeatTimeout
+		}
+	}
+
+	for _, event := range input.Events {
+		if attr := event.GetActivityTaskScheduledEventAttributes(); attr != nil {
+			scheduled[event.GetEventId()] = attr
+		}
+		if attr := event.GetActivityTaskTimedOutEventAttributes(); attr != nil && isHeartbeatTimeout(attr.GetFailure()) {
+			activity := scheduled[attr.GetScheduledEventId()]
+			add(activity.GetActivityType().GetName(), timestamp.DurationValue(activity.GetHeartbeatTimeout()))
+		}
+	}
+	// timeouts of attempts that are retried are only visible in the pending activity
+	for _, activity := range input.Describe.GetPendingActivities() {
+		if isHeartbeatTimeout(activity.GetLastFailure()) {
+			add(activity.GetActivityType().GetName(), 0)
+		}
+	}
+
+	var findings []diagnosisFinding
+	for _, activityType := range types {
+		timeouts := byType[activityType]
+		heartbeat := "the heartbeat timeout"
+		if timeouts.heartbeatTimeout > 0 {
+			heartbeat += " of " + timeouts.heartbeatTimeout.String()
+		}
+		findings = append(findings, diagnosisFinding{
+			Severity: severityWarning,
+			Problem:  fmt.Sprintf("Activity %s timed out on heartbeat %d times", activityType, timeouts.count),
+			Remediation: fmt.Sprintf("Heartbeat more often than %s, or increase it. "+
+				"Heartbeat timeouts also happen when workers crash or shut down while running the activity", heartbeat),
+		})
+	}
+	return findings
+}
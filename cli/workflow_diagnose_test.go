@@ -0,0 +1,192 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	failurepb "go.temporal.io/api/failure/v1"
+	historypb "go.temporal.io/api/history/v1"
+	taskqueuepb "go.temporal.io/api/taskqueue/v1"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/server/common/primitives/timestamp"
+)
+
+var diagnoseNow = time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func workflowTaskCompletedEvent(eventID int64, checksum string) *historypb.HistoryEvent {
+	return &historypb.HistoryEvent{
+		EventId:   eventID,
+		EventType: enumspb.EVENT_TYPE_WORKFLOW_TASK_COMPLETED,
+		Attributes: &historypb.HistoryEvent_WorkflowTaskCompletedEventAttributes{WorkflowTaskCompletedEventAttributes: &historypb.WorkflowTaskCompletedEventAttributes{
+			BinaryChecksum: checksum,
+		}},
+	}
+}
+
+func workflowTaskFailedEvent(eventID int64, cause enumspb.WorkflowTaskFailedCause, message string) *historypb.HistoryEvent {
+	return &historypb.HistoryEvent{
+		EventId:   eventID,
+		EventType: enumspb.EVENT_TYPE_WORKFLOW_TASK_FAILED,
+		Attributes: &historypb.HistoryEvent_WorkflowTaskFailedEventAttributes{WorkflowTaskFailedEventAttributes: &historypb.WorkflowTaskFailedEventAttributes{
+			Cause:          cause,
+			Failure:        &failurepb.Failure{Message: message},
+			Identity:       "worker@host",
+			BinaryChecksum: "bad-checksum",
+		}},
+	}
+}
+
+func diagnosisWithEvents(events ...*historypb.HistoryEvent) *diagnosisInput {
+	return &diagnosisInput{
+		Namespace: "default",
+		Describe:  describeResponse("wid", "rid", "Workflow", enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING),
+		Events:    events,
+		Pollers:   make(map[taskQueueKey]taskQueuePollers),
+		Now:       diagnoseNow,
+	}
+}
+
+func TestDiagnose_NonDeterminism(t *testing.T) {
+	input := diagnosisWithEvents(
+		workflowTaskCompletedEvent(4, "v1"),
+		workflowTaskCompletedEvent(10, "v2"),
+		workflowTaskFailedEvent(14, enumspb.WORKFLOW_TASK_FAILED_CAUSE_NON_DETERMINISTIC_ERROR, "unknown command"),
+	)
+	input.Describe.PendingWorkflowTask = &workflowpb.PendingWorkflowTaskInfo{State: enumspb.PENDING_WORKFLOW_TASK_STATE_STARTED, Attempt: 7}
+
+	findings := diagnose(input, "wid", "rid")
+	require.Len(t, findings, 1)
+	require.Equal(t, severityError, findings[0].Severity)
+	require.Equal(t, "Workflow task fails with a non-determinism error", findings[0].Problem)
+	require.Contains(t, findings[0].Details, "The workflow task has been attempted 7 times")
+	require.Contains(t, findings[0].Details, "Worker binaries that completed workflow tasks, oldest first: v1, v2")
+	require.Contains(t, findings[0].Remediation,
+		`tctl --namespace default workflow reset --workflow-id wid --run-id rid --reset-type BadBinary --reset-bad-binary-checksum v2 --reason "<reason>"`)
+}
+
+func TestDiagnose_BadBinary(t *testing.T) {
+	input := diagnosisWithEvents(workflowTaskFailedEvent(5, enumspb.WORKFLOW_TASK_FAILED_CAUSE_BAD_BINARY, "bad binary"))
+
+	findings := diagnose(input, "wid", "rid")
+	require.Len(t, findings, 1)
+	require.Contains(t, findings[0].Problem, "bad-checksum")
+	require.Contains(t, findings[0].Remediation, "--reset-bad-binary-checksum bad-checksum")
+}
+
+func TestDiagnose_WorkflowTaskRecovered(t *testing.T) {
+	input := diagnosisWithEvents(
+		workflowTaskFailedEvent(5, enumspb.WORKFLOW_TASK_FAILED_CAUSE_WORKFLOW_WORKER_UNHANDLED_FAILURE, "panic"),
+		workflowTaskCompletedEvent(8, "v1"),
+	)
+
+	require.Empty(t, diagnose(input, "wid", "rid"))
+}
+
+func TestDiagnose_WorkflowTaskNotStarted(t *testing.T) {
+	scheduled := diagnoseNow.Add(-5 * time.Minute)
+	key := taskQueueKey{Name: "tq", Type: enumspb.TASK_QUEUE_TYPE_WORKFLOW}
+
+	input := diagnosisWithEvents()
+	input.Describe.ExecutionConfig = &workflowpb.WorkflowExecutionConfig{TaskQueue: &taskqueuepb.TaskQueue{Name: "tq"}}
+	input.Describe.PendingWorkflowTask = &workflowpb.PendingWorkflowTaskInfo{State: enumspb.PENDING_WORKFLOW_TASK_STATE_SCHEDULED, ScheduledTime: &scheduled}
+	require.Equal(t, []taskQueueKey{key}, waitingTaskQueues(input))
+
+	input.Pollers[key] = taskQueuePollers{Live: 0, Total: 1}
+	findings := diagnose(input, "wid", "rid")
+	require.Len(t, findings, 1)
+	require.Equal(t, severityError, findings[0].Severity)
+	require.Equal(t, "Workflow task was scheduled 5m0s ago and never started", findings[0].Problem)
+	require.Contains(t, findings[0].Remediation, "Start workers polling task queue tq")
+
+	input.Pollers[key] = taskQueuePollers{Live: 2, Total: 2}
+	findings = diagnose(input, "wid", "rid")
+	require.Len(t, findings, 1)
+	require.Equal(t, severityWarning, findings[0].Severity)
+}
+
+func TestDiagnose_Activities(t *testing.T) {
+	input := diagnosisWithEvents(
+		&historypb.HistoryEvent{
+			EventId:   5,
+			EventType: enumspb.EVENT_TYPE_ACTIVITY_TASK_SCHEDULED,
+			Attributes: &historypb.HistoryEvent_ActivityTaskScheduledEventAttributes{ActivityTaskScheduledEventAttributes: &historypb.ActivityTaskScheduledEventAttributes{
+				ActivityId:       "5",
+				ActivityType:     &commonpb.ActivityType{Name: "Download"},
+				HeartbeatTimeout: timestamp.DurationPtr(10 * time.Second),
+			}},
+		},
+		&historypb.HistoryEvent{
+			EventId:   7,
+			EventType: enumspb.EVENT_TYPE_ACTIVITY_TASK_TIMED_OUT,
+			Attributes: &historypb.HistoryEvent_ActivityTaskTimedOutEventAttributes{ActivityTaskTimedOutEventAttributes: &historypb.ActivityTaskTimedOutEventAttributes{
+				ScheduledEventId: 5,
+				Failure: &failurepb.Failure{FailureInfo: &failurepb.Failure_TimeoutFailureInfo{TimeoutFailureInfo: &failurepb.TimeoutFailureInfo{
+					TimeoutType: enumspb.TIMEOUT_TYPE_HEARTBEAT,
+				}}},
+			}},
+		},
+	)
+	input.Describe.PendingActivities = []*workflowpb.PendingActivityInfo{{
+		ActivityId:      "9",
+		ActivityType:    &commonpb.ActivityType{Name: "Download"},
+		State:           enumspb.PENDING_ACTIVITY_STATE_SCHEDULED,
+		Attempt:         3,
+		MaximumAttempts: 5,
+		LastFailure:     applicationFailure("connection refused", nil),
+	}}
+
+	findings := diagnose(input, "wid", "rid")
+	require.Len(t, findings, 2)
+	require.Equal(t, "Activity 9 (Download) is retrying, attempt 3 of 5", findings[0].Problem)
+	require.Equal(t, []string{"Last failure: ApplicationFailure: connection refused"}, findings[0].Details)
+	require.Equal(t, "Activity Download timed out on heartbeat 1 times", findings[1].Problem)
+	require.Contains(t, findings[1].Remediation, "heartbeat timeout of 10s")
+}
+
+func (s *cliAppSuite) TestDiagnoseWorkflow() {
+	scheduled := time.Now().Add(-10 * time.Minute)
+	resp := describeResponse("wid", "rid", "Workflow", enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING)
+	resp.ExecutionConfig = &workflowpb.WorkflowExecutionConfig{TaskQueue: &taskqueuepb.TaskQueue{Name: "tq"}}
+	resp.PendingWorkflowTask = &workflowpb.PendingWorkflowTaskInfo{State: enumspb.PENDING_WORKFLOW_TASK_STATE_SCHEDULED, ScheduledTime: &scheduled}
+	s.sdkClient.On("DescribeWorkflowExecution", mock.Anything, "wid", "").Return(resp, nil).Once()
+	s.sdkClient.On("GetWorkflowHistory", mock.Anything, "wid", "rid", false, enumspb.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT).Return(eventsIterator(), nil).Once()
+	s.frontendClient.EXPECT().DescribeTaskQueue(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *workflowservice.DescribeTaskQueueRequest, _ ...interface{}) (*workflowservice.DescribeTaskQueueResponse, error) {
+			s.Equal("tq", req.GetTaskQueue().GetName())
+			s.Equal(enumspb.TASK_QUEUE_TYPE_WORKFLOW, req.GetTaskQueueType())
+			return &workflowservice.DescribeTaskQueueResponse{}, nil
+		}).Times(1)
+
+	err := s.app.Run([]string{"", "--namespace", cliTestNamespace, "workflow", "diagnose", "--workflow-id", "wid"})
+	s.Nil(err)
+	s.sdkClient.AssertExpectations(s.T())
+}
@@ -0,0 +1,300 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/server/common/primitives/timestamp"
+	"golang.org/x/time/rate"
+)
+
+// checks of "workflow find-stuck"
+const (
+	stuckCheckWorkflowTask  = "WorkflowTaskFailing"
+	stuckCheckActivity      = "ActivityRetrying"
+	stuckCheckNoProgress    = "NoProgress"
+	stuckCheckHistoryLength = "HistoryLength"
+)
+
+// stuckWorkflow is a line of the find-stuck report, it can be used as input of "workflow reset-batch" and "workflow terminate"
+type stuckWorkflow struct {
+	WorkflowID   string        `json:"workflowId"`
+	RunID        string        `json:"runId"`
+	WorkflowType string        `json:"workflowType,omitempty"`
+	Reasons      []stuckReason `json:"reasons"`
+}
+
+type stuckReason struct {
+	Check   string `json:"check"`
+	Details string `json:"details"`
+}
+
+// stuckThresholds are the limits above which a workflow is reported, zero disables a check
+type stuckThresholds struct {
+	WorkflowTaskAttempts int32
+	ActivityAttempts     int32
+	NoProgress           time.Duration
+	HistoryLength        int64
+}
+
+// FindStuckWorkflows scans the running workflows matching a query and writes the ones that look stuck as JSON lines
+func FindStuckWorkflows(c *cli.Context) error {
+	namespace, err := getRequiredGlobalOption(c, FlagNamespace)
+	if err != nil {
+		return err
+	}
+	sdkClient, err := getSDKClient(c)
+	if err != nil {
+		return err
+	}
+	thresholds, err := parseStuckThresholds(c)
+	if err != nil {
+		return err
+	}
+	concurrency := c.Int(FlagConcurrency)
+	if concurrency < 1 {
+		return fmt.Errorf("--%s must be at least 1", FlagConcurrency)
+	}
+	rps := c.Int(FlagRPS)
+	if rps < 1 {
+		return fmt.Errorf("--%s must be at least 1", FlagRPS)
+	}
+	query := c.String(FlagListQuery)
+
+	var report io.Writer = os.Stdout
+	// the summary must not mix with the report
+	var summary io.Writer = os.Stderr
+	if fileName := c.String(FlagOutputFilename); fileName != "" {
+		file, err := os.Create(fileName)
+		if err != nil {
+			return fmt.Errorf("unable to create report file: %s", err)
+		}
+		defer file.Close()
+		report = file
+		summary = os.Stdout
+	}
+	encoder := json.NewEncoder(report)
+
+	ctx, cancel := newIndefiniteContext(c)
+	defer cancel()
+	// shared by scan and describe calls, so that the scan doesn't put more load on the cluster than requested
+	limiter := rate.NewLimiter(rate.Limit(rps), 1)
+	frontendClient, err := cFactory.FrontendClient(c)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
+	executions := make(chan *commonpb.WorkflowExecution)
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	var stuck, failed int
+	var reportErr error
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for execution := range executions {
+				workflow, err := inspectWorkflow(ctx, c, limiter, frontendClient, namespace, execution, thresholds, now)
+
+				lock.Lock()
+				switch {
+				case err != nil:
+					failed++
+					fmt.Fprintf(os.Stderr, "failed to inspect workflow %s: %s\n", execution.GetWorkflowId(), err)
+				case workflow != nil && reportErr == nil:
+					stuck++
+					reportErr = encoder.Encode(workflow)
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+
+	var scanErr error
+	scanned := 0
+	var nextPageToken []byte
+	for {
+		if scanErr = limiter.Wait(ctx); scanErr != nil {
+			break
+		}
+		var page []*workflowpb.WorkflowExecutionInfo
+		page, nextPageToken, scanErr = scanWorkflowExecutions(sdkClient, defaultPageSizeForScan, nextPageToken, query, c)
+		if scanErr != nil {
+			break
+		}
+		for _, info := range page {
+			executions <- info.GetExecution()
+			scanned++
+		}
+		if len(nextPageToken) == 0 {
+			break
+		}
+	}
+	close(executions)
+	wg.Wait()
+	if scanErr != nil {
+		return scanErr
+	}
+	if reportErr != nil {
+		return fmt.Errorf("unable to write report: %s", reportErr)
+	}
+
+	fmt.Fprintf(summary, "Scanned %d workflows, %d may be stuck", scanned, stuck)
+	if failed > 0 {
+		fmt.Fprintf(summary, ", failed to inspect %d", failed)
+	}
+	fmt.Fprintln(summary)
+	return nil
+}
+
+func parseStuckThresholds(c *cli.Context) (stuckThresholds, error) {
+	thresholds := stuckThresholds{
+		WorkflowTaskAttempts: int32(c.Int(FlagMaxWorkflowTaskAttempts)),
+		ActivityAttempts:     int32(c.Int(FlagMaxActivityAttempts)),
+		HistoryLength:        c.Int64(FlagHistoryLength),
+	}
+	noProgress, err := timestamp.ParseDuration(c.String(FlagNoProgress))
+	if err != nil {
+		return thresholds, fmt.Errorf("invalid --%s: %s", FlagNoProgress, err)
+	}
+	thresholds.NoProgress = noProgress
+	return thresholds, nil
+}
+
+// inspectWorkflow returns the workflow if it looks stuck, nil otherwise
+func inspectWorkflow(
+	ctx context.Context,
+	c *cli.Context,
+	limiter *rate.Limiter,
+	frontendClient workflowservice.WorkflowServiceClient,
+	namespace string,
+	execution *commonpb.WorkflowExecution,
+	thresholds stuckThresholds,
+	now time.Time,
+) (*stuckWorkflow, error) {
+	if err := limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	rpcCtx, cancel := newContext(c)
+	resp, err := frontendClient.DescribeWorkflowExecution(rpcCtx, &workflowservice.DescribeWorkflowExecutionRequest{
+		Namespace: namespace,
+		Execution: execution,
+	})
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("workflow describe failed: %s", err)
+	}
+	// the workflow may have closed since it was scanned
+	if resp.GetWorkflowExecutionInfo().GetStatus() != enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING {
+		return nil, nil
+	}
+
+	var lastEventTime *time.Time
+	if thresholds.NoProgress > 0 {
+		if err := limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		// the timeout starts after the wait for the limiter
+		rpcCtx, cancel := newContext(c)
+		history, err := frontendClient.GetWorkflowExecutionHistoryReverse(rpcCtx, &workflowservice.GetWorkflowExecutionHistoryReverseRequest{
+			Namespace:       namespace,
+			Execution:       execution,
+			MaximumPageSize: 1,
+		})
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the last event: %s", err)
+		}
+		if events := history.GetHistory().GetEvents(); len(events) > 0 {
+			lastEventTime = events[0].GetEventTime()
+		}
+	}
+
+	reasons := stuckReasons(resp, lastEventTime, thresholds, now)
+	if len(reasons) == 0 {
+		return nil, nil
+	}
+	info := resp.GetWorkflowExecutionInfo()
+	return &stuckWorkflow{
+		WorkflowID:   info.GetExecution().GetWorkflowId(),
+		RunID:        info.GetExecution().GetRunId(),
+		WorkflowType: info.GetType().GetName(),
+		Reasons:      reasons,
+	}, nil
+}
+
+// stuckReasons returns the checks a running workflow fails
+func stuckReasons(resp *workflowservice.DescribeWorkflowExecutionResponse, lastEventTime *time.Time, thresholds stuckThresholds, now time.Time) []stuckReason {
+	var reasons []stuckReason
+
+	if attempt := resp.GetPendingWorkflowTask().GetAttempt(); thresholds.WorkflowTaskAttempts > 0 && attempt > thresholds.WorkflowTaskAttempts {
+		reasons = append(reasons, stuckReason{
+			Check:   stuckCheckWorkflowTask,
+			Details: fmt.Sprintf("workflow task is at attempt %d", attempt),
+		})
+	}
+
+	if thresholds.ActivityAttempts > 0 {
+		for _, activity := range resp.GetPendingActivities() {
+			if activity.GetAttempt() <= thresholds.ActivityAttempts {
+				continue
+			}
+			details := fmt.Sprintf("activity %s (%s) is at attempt %d", activity.GetActivityId(), activity.GetActivityType().GetName(), activity.GetAttempt())
+			if message := activity.GetLastFailure().GetMessage(); message != "" {
+				details += ", last failure: " + message
+			}
+			reasons = append(reasons, stuckReason{Check: stuckCheckActivity, Details: details})
+		}
+	}
+
+	if lastEventTime != nil && thresholds.NoProgress > 0 {
+		if idle := now.Sub(*lastEventTime); idle > thresholds.NoProgress {
+			reasons = append(reasons, stuckReason{
+				Check:   stuckCheckNoProgress,
+				Details: fmt.Sprintf("no new event for %s", roundDuration(idle)),
+			})
+		}
+	}
+
+	if length := resp.GetWorkflowExecutionInfo().GetHistoryLength(); thresholds.HistoryLength > 0 && length >= thresholds.HistoryLength {
+		reasons = append(reasons, stuckReason{
+			Check:   stuckCheckHistoryLength,
+			Details: fmt.Sprintf("history has %d events", length),
+		})
+	}
+
+	return reasons
+}
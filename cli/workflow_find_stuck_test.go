@@ -0,0 +1,183 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	historypb "go.temporal.io/api/history/v1"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+var findStuckThresholds = stuckThresholds{
+	WorkflowTaskAttempts: 3,
+	ActivityAttempts:     10,
+	NoProgress:           time.Hour,
+	HistoryLength:        40000,
+}
+
+func TestStuckReasons(t *testing.T) {
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	resp := describeResponse("wid", "rid", "Workflow", enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING)
+	lastEventTime := now.Add(-time.Minute)
+	require.Empty(t, stuckReasons(resp, &lastEventTime, findStuckThresholds, now))
+
+	resp.PendingWorkflowTask = &workflowpb.PendingWorkflowTaskInfo{Attempt: 4}
+	resp.PendingActivities = []*workflowpb.PendingActivityInfo{
+		{ActivityId: "5", ActivityType: &commonpb.ActivityType{Name: "Download"}, Attempt: 11, LastFailure: applicationFailure("connection refused", nil)},
+		{ActivityId: "6", ActivityType: &commonpb.ActivityType{Name: "Upload"}, Attempt: 10},
+	}
+	resp.WorkflowExecutionInfo.HistoryLength = 45000
+	lastEventTime = now.Add(-2 * time.Hour)
+	require.Equal(t, []stuckReason{
+		{Check: stuckCheckWorkflowTask, Details: "workflow task is at attempt 4"},
+		{Check: stuckCheckActivity, Details: "activity 5 (Download) is at attempt 11, last failure: connection refused"},
+		{Check: stuckCheckNoProgress, Details: "no new event for 2h0m0s"},
+		{Check: stuckCheckHistoryLength, Details: "history has 45000 events"},
+	}, stuckReasons(resp, &lastEventTime, findStuckThresholds, now))
+
+	require.Empty(t, stuckReasons(resp, &lastEventTime, stuckThresholds{}, now))
+}
+
+func TestParseExecutionLine(t *testing.T) {
+	tests := []struct {
+		line string
+		wid  string
+		rid  string
+		err  bool
+	}{
+		{line: "wid", wid: "wid"},
+		{line: "wid\trid", wid: "wid", rid: "rid"},
+		{line: `{"workflowId":"wid","runId":"rid","reasons":[]}`, wid: "wid", rid: "rid"},
+		{line: `{"runId":"rid"}`, err: true},
+		{line: `{"workflowId":`, err: true},
+	}
+	for _, test := range tests {
+		wid, rid, err := parseExecutionLine(test.line, "\t")
+		if test.err {
+			require.Error(t, err, test.line)
+			continue
+		}
+		require.NoError(t, err, test.line)
+		require.Equal(t, test.wid, wid)
+		require.Equal(t, test.rid, rid)
+	}
+}
+
+func (s *cliAppSuite) TestFindStuckWorkflows() {
+	s.sdkClient.On("ScanWorkflow", mock.Anything, mock.Anything).Return(&workflowservice.ScanWorkflowExecutionsResponse{
+		Executions: []*workflowpb.WorkflowExecutionInfo{
+			{Execution: &commonpb.WorkflowExecution{WorkflowId: "stuck", RunId: "rid-1"}},
+			{Execution: &commonpb.WorkflowExecution{WorkflowId: "healthy", RunId: "rid-2"}},
+		},
+	}, nil).Once()
+	s.frontendClient.EXPECT().DescribeWorkflowExecution(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, req *workflowservice.DescribeWorkflowExecutionRequest, _ ...interface{}) (*workflowservice.DescribeWorkflowExecutionResponse, error) {
+			execution := req.GetExecution()
+			resp := describeResponse(execution.GetWorkflowId(), execution.GetRunId(), "Workflow", enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING)
+			if execution.GetWorkflowId() == "stuck" {
+				resp.PendingWorkflowTask = &workflowpb.PendingWorkflowTaskInfo{Attempt: 20}
+			}
+			return resp, nil
+		}).Times(2)
+	lastEventTime := time.Now()
+	s.frontendClient.EXPECT().GetWorkflowExecutionHistoryReverse(gomock.Any(), gomock.Any()).Return(&workflowservice.GetWorkflowExecutionHistoryReverseResponse{
+		History: &historypb.History{Events: []*historypb.HistoryEvent{{EventId: 10, EventTime: &lastEventTime}}},
+	}, nil).Times(2)
+
+	file := filepath.Join(s.T().TempDir(), "stuck.jsonl")
+	err := s.app.Run([]string{"", "--namespace", cliTestNamespace, "workflow", "find-stuck", "--output-filename", file})
+	s.Nil(err)
+	s.sdkClient.AssertExpectations(s.T())
+
+	content, err := os.ReadFile(file)
+	s.NoError(err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	s.Len(lines, 1)
+	var workflow stuckWorkflow
+	s.NoError(json.Unmarshal([]byte(lines[0]), &workflow))
+	s.Equal(stuckWorkflow{
+		WorkflowID:   "stuck",
+		RunID:        "rid-1",
+		WorkflowType: "Workflow",
+		Reasons:      []stuckReason{{Check: stuckCheckWorkflowTask, Details: "workflow task is at attempt 20"}},
+	}, workflow)
+}
+
+func (s *cliAppSuite) TestTerminateWorkflow_InputFile() {
+	file := filepath.Join(s.T().TempDir(), "stuck.jsonl")
+	s.NoError(os.WriteFile(file, []byte(`{"workflowId":"wid-1","runId":"rid-1","reasons":[]}`+"\n"+"wid-2\n"), 0600))
+	s.sdkClient.On("TerminateWorkflow", mock.Anything, "wid-1", "rid-1", "stuck", mock.Anything).Return(nil).Once()
+	s.sdkClient.On("TerminateWorkflow", mock.Anything, "wid-2", "", "stuck", mock.Anything).Return(nil).Once()
+
+	err := s.app.Run([]string{"", "--namespace", cliTestNamespace, "workflow", "terminate", "--input-file", file, "--reason", "stuck", "--yes"})
+	s.Nil(err)
+	s.sdkClient.AssertExpectations(s.T())
+}
+
+func (s *cliAppSuite) TestTerminateWorkflow_InputFileNotConfirmed() {
+	dir := s.T().TempDir()
+	file := filepath.Join(dir, "stuck.jsonl")
+	s.NoError(os.WriteFile(file, []byte("wid-1\nwid-2\n"), 0600))
+	stdin, err := os.Create(filepath.Join(dir, "stdin"))
+	s.NoError(err)
+	defer stdin.Close()
+	_, err = stdin.WriteString("no\n")
+	s.NoError(err)
+	_, err = stdin.Seek(0, 0)
+	s.NoError(err)
+	defer func(f *os.File) { os.Stdin = f }(os.Stdin)
+	os.Stdin = stdin
+
+	out := captureStdout(s.T(), func() {
+		err = s.app.Run([]string{"", "--namespace", cliTestNamespace, "workflow", "terminate", "--input-file", file, "--reason", "stuck"})
+		s.Nil(err)
+	})
+	s.Contains(out, "This will terminate 2 workflows")
+	s.Contains(out, "Workflows are not terminated")
+	s.sdkClient.AssertNotCalled(s.T(), "TerminateWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *cliAppSuite) TestTerminateWorkflow_InputFileDryRun() {
+	file := filepath.Join(s.T().TempDir(), "stuck.jsonl")
+	s.NoError(os.WriteFile(file, []byte("wid-1\trid-1\nwid-2\n"), 0600))
+
+	out := captureStdout(s.T(), func() {
+		err := s.app.Run([]string{"", "--namespace", cliTestNamespace, "workflow", "terminate", "--input-file", file, "--reason", "stuck", "--dry-run"})
+		s.Nil(err)
+	})
+	s.Equal("wid-1\trid-1\nwid-2\t\nWould terminate 2 workflows\n", out)
+	s.sdkClient.AssertNotCalled(s.T(), "TerminateWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
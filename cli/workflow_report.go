@@ -0,0 +1,262 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	failurepb "go.temporal.io/api/failure/v1"
+	historypb "go.temporal.io/api/history/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/server/common/codec"
+)
+
+type reportEvent struct {
+	ID      int64
+	Time    string
+	Type    string
+	Details string
+	Failed  bool
+}
+
+type reportPendingActivity struct {
+	ActivityID    string
+	Type          string
+	State         string
+	Attempt       string
+	ScheduledTime string
+	LastStarted   string
+	LastHeartbeat string
+	LastWorker    string
+	LastFailure   string
+}
+
+type workflowReport struct {
+	WorkflowID    string
+	RunID         string
+	Type          string
+	Status        string
+	StartTime     string
+	CloseTime     string
+	HistoryLength int64
+	GeneratedAt   string
+	// FailureEvent describes the event the failure chain comes from
+	FailureEvent      string
+	Failures          []failureLevel
+	PendingActivities []reportPendingActivity
+	Timeline          *timelineHTMLData
+	Describe          string
+	Events            []reportEvent
+}
+
+// WorkflowReport writes a self-contained HTML report of a workflow execution, for postmortems
+func WorkflowReport(c *cli.Context) error {
+	sdkClient, err := getSDKClient(c)
+	if err != nil {
+		return err
+	}
+	wid := c.String(FlagWorkflowID)
+	rid := c.String(FlagRunID)
+	file := c.String(FlagOut)
+
+	ctx, cancel := newIndefiniteContext(c)
+	defer cancel()
+
+	resp, err := sdkClient.DescribeWorkflowExecution(ctx, wid, rid)
+	if err != nil {
+		return fmt.Errorf("workflow describe failed: %s", err)
+	}
+	// describe the same run as the history even if a new run starts meanwhile
+	rid = resp.GetWorkflowExecutionInfo().GetExecution().GetRunId()
+	events, err := getHistoryEvents(ctx, sdkClient, wid, rid)
+	if err != nil {
+		return fmt.Errorf("unable to read workflow history: %s", err)
+	}
+
+	report, err := newWorkflowReport(c, resp, events, time.Now())
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("unable to create report file: %s", err)
+	}
+	defer f.Close()
+	if err := writeWorkflowReport(f, report); err != nil {
+		return fmt.Errorf("unable to write report: %s", err)
+	}
+
+	fmt.Printf("Report written to %s\n", file)
+	return nil
+}
+
+func newWorkflowReport(c *cli.Context, resp *workflowservice.DescribeWorkflowExecutionResponse, events []*historypb.HistoryEvent, now time.Time) (*workflowReport, error) {
+	info := resp.GetWorkflowExecutionInfo()
+	report := &workflowReport{
+		WorkflowID:    info.GetExecution().GetWorkflowId(),
+		RunID:         info.GetExecution().GetRunId(),
+		Type:          info.GetType().GetName(),
+		Status:        info.GetStatus().String(),
+		StartTime:     formatReportTime(info.GetStartTime()),
+		CloseTime:     formatReportTime(info.GetCloseTime()),
+		HistoryLength: info.GetHistoryLength(),
+		GeneratedAt:   now.Format(defaultDateTimeFormat),
+	}
+
+	describe, err := codec.NewJSONPBIndentEncoder("  ").Encode(convertDescribeWorkflowExecutionResponse(c, resp))
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode workflow description: %s", err)
+	}
+	report.Describe = string(describe)
+
+	for _, event := range events {
+		report.Events = append(report.Events, reportEvent{
+			ID:      event.GetEventId(),
+			Time:    formatReportTime(event.GetEventTime()),
+			Type:    event.GetEventType().String(),
+			Details: historyEventToPlainString(event),
+			Failed:  eventFailure(event) != nil,
+		})
+	}
+
+	if failure, event := lastFailure(events); failure != nil {
+		report.FailureEvent = fmt.Sprintf("%s (event %d)", event.GetEventType(), event.GetEventId())
+		report.Failures = failureLevels(failure)
+	}
+
+	for _, activity := range resp.GetPendingActivities() {
+		pending := reportPendingActivity{
+			ActivityID:    activity.GetActivityId(),
+			Type:          activity.GetActivityType().GetName(),
+			State:         activity.GetState().String(),
+			Attempt:       fmt.Sprintf("%d", activity.GetAttempt()),
+			ScheduledTime: formatReportTime(activity.GetScheduledTime()),
+			LastStarted:   formatReportTime(activity.GetLastStartedTime()),
+			LastHeartbeat: formatReportTime(activity.GetLastHeartbeatTime()),
+			LastWorker:    activity.GetLastWorkerIdentity(),
+		}
+		if activity.GetMaximumAttempts() > 0 {
+			pending.Attempt += fmt.Sprintf(" of %d", activity.GetMaximumAttempts())
+		}
+		if f := activity.GetLastFailure(); f != nil {
+			pending.LastFailure = f.GetMessage()
+		}
+		report.PendingActivities = append(report.PendingActivities, pending)
+	}
+
+	timeline := buildTimeline(events, now)
+	timeline.WorkflowID = report.WorkflowID
+	timeline.RunID = report.RunID
+	report.Timeline = newTimelineHTMLData(timeline)
+
+	return report, nil
+}
+
+// lastFailure returns the failure of the workflow if it failed, otherwise the most recent failure in its history
+func lastFailure(events []*historypb.HistoryEvent) (*failurepb.Failure, *historypb.HistoryEvent) {
+	for i := len(events) - 1; i >= 0; i-- {
+		if failure := eventFailure(events[i]); failure != nil {
+			return failure, events[i]
+		}
+	}
+	return nil, nil
+}
+
+func formatReportTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(defaultDateTimeFormat)
+}
+
+var workflowReportTemplate = template.Must(template.Must(timelineHTMLParts.Clone()).New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Workflow report: {{.WorkflowID}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #212121; }
+h2 { border-bottom: 1px solid #e0e0e0; padding-bottom: 4px; margin-top: 2em; }
+table.data { border-collapse: collapse; }
+table.data th, table.data td { border: 1px solid #e0e0e0; padding: 4px 8px; text-align: left; vertical-align: top; font-size: 13px; }
+table.data th { background: #f5f5f5; }
+tr.failed td { background: #ffebee; }
+pre { margin: 0; white-space: pre-wrap; word-break: break-all; font-size: 12px; }
+.failure { border-left: 4px solid #e53935; padding: 4px 12px; margin: 8px 0; }
+{{template "timelineStyle"}}
+</style>
+</head>
+<body>
+<h1>{{.WorkflowID}}</h1>
+<table class="data">
+<tr><th>Run ID</th><td>{{.RunID}}</td></tr>
+<tr><th>Type</th><td>{{.Type}}</td></tr>
+<tr><th>Status</th><td>{{.Status}}</td></tr>
+<tr><th>Start time</th><td>{{.StartTime}}</td></tr>
+{{if .CloseTime}}<tr><th>Close time</th><td>{{.CloseTime}}</td></tr>
+{{end}}<tr><th>History length</th><td>{{.HistoryLength}}</td></tr>
+<tr><th>Report generated</th><td>{{.GeneratedAt}}</td></tr>
+</table>
+
+<h2>Failure</h2>
+{{if .Failures}}<p>From {{.FailureEvent}}</p>
+{{range .Failures}}<div class="failure">
+<p><b>{{.Type}}</b>{{if .Source}} from {{.Source}}{{end}}: {{.Message}}</p>
+{{if .Details}}<p>Details: <code>{{.Details}}</code></p>{{end}}
+{{if .StackTrace}}<pre>{{.StackTrace}}</pre>{{end}}
+</div>
+{{end}}{{else}}<p>No failures.</p>
+{{end}}
+<h2>Pending activities</h2>
+{{if .PendingActivities}}<table class="data">
+<tr><th>Activity ID</th><th>Type</th><th>State</th><th>Attempt</th><th>Scheduled</th><th>Last started</th><th>Last heartbeat</th><th>Last worker</th><th>Last failure</th></tr>
+{{range .PendingActivities}}<tr><td>{{.ActivityID}}</td><td>{{.Type}}</td><td>{{.State}}</td><td>{{.Attempt}}</td><td>{{.ScheduledTime}}</td><td>{{.LastStarted}}</td><td>{{.LastHeartbeat}}</td><td>{{.LastWorker}}</td><td>{{.LastFailure}}</td></tr>
+{{end}}</table>
+{{else}}<p>No pending activities.</p>
+{{end}}
+<h2>Timeline</h2>
+{{if .Timeline.Rows}}{{template "timelineChart" .Timeline}}{{else}}<p>No activities, timers or child workflows.</p>{{end}}
+
+<h2>Description</h2>
+<details><summary>workflow describe</summary>
+<pre>{{.Describe}}</pre>
+</details>
+
+<h2>History</h2>
+<table class="data">
+<tr><th>ID</th><th>Time</th><th>Type</th><th>Details</th></tr>
+{{range .Events}}<tr{{if .Failed}} class="failed"{{end}}><td>{{.ID}}</td><td>{{.Time}}</td><td>{{.Type}}</td><td><pre>{{.Details}}</pre></td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+func writeWorkflowReport(w io.Writer, report *workflowReport) error {
+	return workflowReportTemplate.Execute(w, report)
+}
@@ -0,0 +1,126 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	failurepb "go.temporal.io/api/failure/v1"
+	historypb "go.temporal.io/api/history/v1"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/server/common/payloads"
+	"go.temporal.io/server/common/primitives/timestamp"
+)
+
+func applicationFailure(message string, cause *failurepb.Failure) *failurepb.Failure {
+	return &failurepb.Failure{
+		Message:     message,
+		Source:      "GoSDK",
+		StackTrace:  "main.Activity()\n",
+		Cause:       cause,
+		FailureInfo: &failurepb.Failure_ApplicationFailureInfo{ApplicationFailureInfo: &failurepb.ApplicationFailureInfo{}},
+	}
+}
+
+func reportHistory() []*historypb.HistoryEvent {
+	return append(timelineHistory(),
+		&historypb.HistoryEvent{
+			EventId:   20,
+			EventTime: timestamp.TimePtr(timelineStart.Add(45 * time.Second)),
+			EventType: enumspb.EVENT_TYPE_ACTIVITY_TASK_FAILED,
+			Attributes: &historypb.HistoryEvent_ActivityTaskFailedEventAttributes{ActivityTaskFailedEventAttributes: &historypb.ActivityTaskFailedEventAttributes{
+				ScheduledEventId: 16,
+				Failure:          applicationFailure("shipping failed", applicationFailure("connection refused", nil)),
+			}},
+		},
+		&historypb.HistoryEvent{
+			EventId:   21,
+			EventTime: timestamp.TimePtr(timelineStart.Add(45 * time.Second)),
+			EventType: enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_SIGNALED,
+			Attributes: &historypb.HistoryEvent_WorkflowExecutionSignaledEventAttributes{WorkflowExecutionSignaledEventAttributes: &historypb.WorkflowExecutionSignaledEventAttributes{
+				SignalName: "address",
+				Input:      payloads.EncodeString("221B Baker Street"),
+			}},
+		},
+	)
+}
+
+func TestLastFailure(t *testing.T) {
+	failure, event := lastFailure(reportHistory())
+	require.Equal(t, int64(20), event.GetEventId())
+	require.Equal(t, "shipping failed", failure.GetMessage())
+
+	failure, event = lastFailure(timelineHistory())
+	require.Nil(t, failure)
+	require.Nil(t, event)
+}
+
+func (s *cliAppSuite) TestWorkflowReport() {
+	s.sdkClient.On("DescribeWorkflowExecution", mock.Anything, "wid", "").Return(&workflowservice.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: &workflowpb.WorkflowExecutionInfo{
+			Execution: &commonpb.WorkflowExecution{WorkflowId: "wid", RunId: "rid"},
+			Type:      &commonpb.WorkflowType{Name: "Order"},
+			Status:    enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING,
+			StartTime: timestamp.TimePtr(timelineStart),
+		},
+		PendingActivities: []*workflowpb.PendingActivityInfo{{
+			ActivityId:   "16",
+			ActivityType: &commonpb.ActivityType{Name: "Ship"},
+			State:        enumspb.PENDING_ACTIVITY_STATE_SCHEDULED,
+			Attempt:      2,
+			LastFailure:  applicationFailure("shipping failed", nil),
+		}},
+	}, nil).Once()
+	s.sdkClient.On("GetWorkflowHistory", mock.Anything, "wid", "rid", false, enumspb.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT).Return(eventsIterator(reportHistory()...)).Once()
+
+	// the report is written without colors even if the terminal is colored
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	file := filepath.Join(s.T().TempDir(), "report.html")
+	err := s.app.Run([]string{"", "--namespace", cliTestNamespace, "--color", "always", "workflow", "report", "--workflow-id", "wid", "--out", file})
+	s.NoError(err)
+	s.sdkClient.AssertExpectations(s.T())
+
+	data, err := os.ReadFile(file)
+	s.NoError(err)
+	report := string(data)
+	s.Contains(report, "<h1>wid</h1>")
+	s.Contains(report, "From ActivityTaskFailed (event 20)")
+	s.Contains(report, "connection refused")
+	s.Contains(report, "<td>Ship</td><td>Scheduled</td><td>2</td>")
+	s.Contains(report, `class="segment retrying"`)
+	// payloads are decoded with the data converter
+	s.Contains(report, "221B Baker Street")
+	s.Contains(report, `&#34;workflowExecutionInfo&#34;`)
+	s.Contains(report, `<tr class="failed"><td>20</td>`)
+	s.Contains(report, "Failure:\n  ApplicationFailure")
+	s.NotContains(report, "\x1b[")
+}
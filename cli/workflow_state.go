@@ -0,0 +1,360 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/urfave/cli/v2"
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	historypb "go.temporal.io/api/history/v1"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/server/common/codec"
+	"go.temporal.io/server/common/primitives/timestamp"
+)
+
+// workflowState is the state of a workflow execution rebuilt from its history
+type workflowState struct {
+	eventID             int64
+	info                *workflowpb.WorkflowExecutionInfo
+	config              *workflowpb.WorkflowExecutionConfig
+	pendingWorkflowTask *workflowpb.PendingWorkflowTaskInfo
+	// keyed by the Id of the scheduled or initiated event
+	activities map[int64]*workflowpb.PendingActivityInfo
+	children   map[int64]*workflowpb.PendingChildExecutionInfo
+	timers     map[string]*pendingTimer
+	signals    []*receivedSignal
+	// the last completed workflow task
+	lastWorkflowTask *completedWorkflowTask
+}
+
+type pendingTimer struct {
+	TimerID        string     `json:"timerId"`
+	StartedEventID int64      `json:"startedEventId"`
+	StartTime      *time.Time `json:"startTime,omitempty"`
+	FireTime       *time.Time `json:"fireTime,omitempty"`
+}
+
+type receivedSignal struct {
+	EventID    int64      `json:"eventId"`
+	EventTime  *time.Time `json:"eventTime,omitempty"`
+	SignalName string     `json:"signalName"`
+	Input      string     `json:"input,omitempty"`
+	Identity   string     `json:"identity,omitempty"`
+}
+
+type completedWorkflowTask struct {
+	ScheduledEventID int64      `json:"scheduledEventId"`
+	StartedEventID   int64      `json:"startedEventId"`
+	CompletedEventID int64      `json:"completedEventId"`
+	CompletedTime    *time.Time `json:"completedTime,omitempty"`
+	Identity         string     `json:"identity,omitempty"`
+	BinaryChecksum   string     `json:"binaryChecksum,omitempty"`
+}
+
+// workflowStateOutput is printed by "workflow describe --at-event", proto messages are encoded like in "workflow describe"
+type workflowStateOutput struct {
+	EventID                   int64                  `json:"eventId"`
+	ExecutionConfig           json.RawMessage        `json:"executionConfig,omitempty"`
+	WorkflowExecutionInfo     json.RawMessage        `json:"workflowExecutionInfo"`
+	PendingActivities         []json.RawMessage      `json:"pendingActivities,omitempty"`
+	PendingChildren           []json.RawMessage      `json:"pendingChildren,omitempty"`
+	PendingWorkflowTask       json.RawMessage        `json:"pendingWorkflowTask,omitempty"`
+	PendingTimers             []*pendingTimer        `json:"pendingTimers,omitempty"`
+	SignalsReceived           []*receivedSignal      `json:"signalsReceived,omitempty"`
+	LastCompletedWorkflowTask *completedWorkflowTask `json:"lastCompletedWorkflowTask,omitempty"`
+}
+
+// DescribeWorkflowAtEvent shows the state of a workflow execution right after the given event, rebuilt from its history
+func DescribeWorkflowAtEvent(c *cli.Context) error {
+	sdkClient, err := getSDKClient(c)
+	if err != nil {
+		return err
+	}
+	wid := c.String(FlagWorkflowID)
+	atEvent := c.Int64(FlagAtEvent)
+	if atEvent < 1 {
+		return fmt.Errorf("--%s must be at least 1", FlagAtEvent)
+	}
+
+	ctx, cancel := newIndefiniteContext(c)
+	defer cancel()
+
+	rid, err := resolveRunID(ctx, sdkClient, wid, c.String(FlagRunID))
+	if err != nil {
+		return err
+	}
+	events, err := getHistoryEvents(ctx, sdkClient, wid, rid)
+	if err != nil {
+		return fmt.Errorf("unable to read workflow history: %s", err)
+	}
+	if int64(len(events)) < atEvent {
+		return fmt.Errorf("workflow history has only %d events", len(events))
+	}
+
+	state := newWorkflowState(wid, rid)
+	for _, event := range events[:atEvent] {
+		state.apply(event)
+	}
+
+	out, err := state.output(c)
+	if err != nil {
+		return err
+	}
+	prettyPrintJSONObject(out)
+	return nil
+}
+
+func newWorkflowState(wid, rid string) *workflowState {
+	return &workflowState{
+		info: &workflowpb.WorkflowExecutionInfo{
+			Execution: &commonpb.WorkflowExecution{WorkflowId: wid, RunId: rid},
+		},
+		activities: make(map[int64]*workflowpb.PendingActivityInfo),
+		children:   make(map[int64]*workflowpb.PendingChildExecutionInfo),
+		timers:     make(map[string]*pendingTimer),
+	}
+}
+
+// apply updates the state with the next event of the history
+func (s *workflowState) apply(event *historypb.HistoryEvent) {
+	s.eventID = event.GetEventId()
+	s.info.HistoryLength = event.GetEventId()
+	eventTime := event.GetEventTime()
+
+	switch event.GetEventType() {
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED:
+		attr := event.GetWorkflowExecutionStartedEventAttributes()
+		s.info.Type = attr.GetWorkflowType()
+		s.info.StartTime = eventTime
+		s.info.Status = enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING
+		s.info.ParentNamespaceId = attr.GetParentWorkflowNamespace()
+		s.info.ParentExecution = attr.GetParentWorkflowExecution()
+		s.info.Memo = attr.GetMemo()
+		s.info.SearchAttributes = attr.GetSearchAttributes()
+		s.config = &workflowpb.WorkflowExecutionConfig{
+			TaskQueue:                  attr.GetTaskQueue(),
+			WorkflowExecutionTimeout:   attr.GetWorkflowExecutionTimeout(),
+			WorkflowRunTimeout:         attr.GetWorkflowRunTimeout(),
+			DefaultWorkflowTaskTimeout: attr.GetWorkflowTaskTimeout(),
+		}
+
+	case enumspb.EVENT_TYPE_WORKFLOW_TASK_SCHEDULED:
+		s.pendingWorkflowTask = &workflowpb.PendingWorkflowTaskInfo{
+			State:                 enumspb.PENDING_WORKFLOW_TASK_STATE_SCHEDULED,
+			ScheduledTime:         eventTime,
+			OriginalScheduledTime: eventTime,
+			Attempt:               event.GetWorkflowTaskScheduledEventAttributes().GetAttempt(),
+		}
+	case enumspb.EVENT_TYPE_WORKFLOW_TASK_STARTED:
+		if s.pendingWorkflowTask != nil {
+			s.pendingWorkflowTask.State = enumspb.PENDING_WORKFLOW_TASK_STATE_STARTED
+			s.pendingWorkflowTask.StartedTime = eventTime
+		}
+	case enumspb.EVENT_TYPE_WORKFLOW_TASK_COMPLETED:
+		attr := event.GetWorkflowTaskCompletedEventAttributes()
+		s.pendingWorkflowTask = nil
+		s.lastWorkflowTask = &completedWorkflowTask{
+			ScheduledEventID: attr.GetScheduledEventId(),
+			StartedEventID:   attr.GetStartedEventId(),
+			CompletedEventID: event.GetEventId(),
+			CompletedTime:    eventTime,
+			Identity:         attr.GetIdentity(),
+			BinaryChecksum:   attr.GetBinaryChecksum(),
+		}
+	case enumspb.EVENT_TYPE_WORKFLOW_TASK_FAILED, enumspb.EVENT_TYPE_WORKFLOW_TASK_TIMED_OUT:
+		s.pendingWorkflowTask = nil
+
+	case enumspb.EVENT_TYPE_ACTIVITY_TASK_SCHEDULED:
+		attr := event.GetActivityTaskScheduledEventAttributes()
+		activity := &workflowpb.PendingActivityInfo{
+			ActivityId:      attr.GetActivityId(),
+			ActivityType:    attr.GetActivityType(),
+			State:           enumspb.PENDING_ACTIVITY_STATE_SCHEDULED,
+			ScheduledTime:   eventTime,
+			Attempt:         1,
+			MaximumAttempts: attr.GetRetryPolicy().GetMaximumAttempts(),
+		}
+		if timeout := timestamp.DurationValue(attr.GetScheduleToCloseTimeout()); timeout > 0 && eventTime != nil {
+			activity.ExpirationTime = timestamp.TimePtr(eventTime.Add(timeout))
+		}
+		s.activities[event.GetEventId()] = activity
+	case enumspb.EVENT_TYPE_ACTIVITY_TASK_STARTED:
+		// the started event of an activity that is retried is only written when the last attempt ends
+		attr := event.GetActivityTaskStartedEventAttributes()
+		if activity, ok := s.activities[attr.GetScheduledEventId()]; ok {
+			activity.State = enumspb.PENDING_ACTIVITY_STATE_STARTED
+			activity.LastStartedTime = eventTime
+			activity.Attempt = attr.GetAttempt()
+			activity.LastFailure = attr.GetLastFailure()
+			activity.LastWorkerIdentity = attr.GetIdentity()
+		}
+	case enumspb.EVENT_TYPE_ACTIVITY_TASK_CANCEL_REQUESTED:
+		if activity, ok := s.activities[event.GetActivityTaskCancelRequestedEventAttributes().GetScheduledEventId()]; ok {
+			activity.State = enumspb.PENDING_ACTIVITY_STATE_CANCEL_REQUESTED
+		}
+	case enumspb.EVENT_TYPE_ACTIVITY_TASK_COMPLETED:
+		delete(s.activities, event.GetActivityTaskCompletedEventAttributes().GetScheduledEventId())
+	case enumspb.EVENT_TYPE_ACTIVITY_TASK_FAILED:
+		delete(s.activities, event.GetActivityTaskFailedEventAttributes().GetScheduledEventId())
+	case enumspb.EVENT_TYPE_ACTIVITY_TASK_TIMED_OUT:
+		delete(s.activities, event.GetActivityTaskTimedOutEventAttributes().GetScheduledEventId())
+	case enumspb.EVENT_TYPE_ACTIVITY_TASK_CANCELED:
+		delete(s.activities, event.GetActivityTaskCanceledEventAttributes().GetScheduledEventId())
+
+	case enumspb.EVENT_TYPE_TIMER_STARTED:
+		attr := event.GetTimerStartedEventAttributes()
+		timer := &pendingTimer{TimerID: attr.GetTimerId(), StartedEventID: event.GetEventId(), StartTime: eventTime}
+		if eventTime != nil {
+			timer.FireTime = timestamp.TimePtr(eventTime.Add(timestamp.DurationValue(attr.GetStartToFireTimeout())))
+		}
+		s.timers[attr.GetTimerId()] = timer
+	case enumspb.EVENT_TYPE_TIMER_FIRED:
+		delete(s.timers, event.GetTimerFiredEventAttributes().GetTimerId())
+	case enumspb.EVENT_TYPE_TIMER_CANCELED:
+		delete(s.timers, event.GetTimerCanceledEventAttributes().GetTimerId())
+
+	case enumspb.EVENT_TYPE_START_CHILD_WORKFLOW_EXECUTION_INITIATED:
+		attr := event.GetStartChildWorkflowExecutionInitiatedEventAttributes()
+		s.children[event.GetEventId()] = &workflowpb.PendingChildExecutionInfo{
+			WorkflowId:        attr.GetWorkflowId(),
+			WorkflowTypeName:  attr.GetWorkflowType().GetName(),
+			InitiatedId:       event.GetEventId(),
+			ParentClosePolicy: attr.GetParentClosePolicy(),
+		}
+	case enumspb.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_STARTED:
+		attr := event.GetChildWorkflowExecutionStartedEventAttributes()
+		if child, ok := s.children[attr.GetInitiatedEventId()]; ok {
+			child.RunId = attr.GetWorkflowExecution().GetRunId()
+		}
+	case enumspb.EVENT_TYPE_START_CHILD_WORKFLOW_EXECUTION_FAILED:
+		delete(s.children, event.GetStartChildWorkflowExecutionFailedEventAttributes().GetInitiatedEventId())
+	case enumspb.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_COMPLETED:
+		delete(s.children, event.GetChildWorkflowExecutionCompletedEventAttributes().GetInitiatedEventId())
+	case enumspb.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_FAILED:
+		delete(s.children, event.GetChildWorkflowExecutionFailedEventAttributes().GetInitiatedEventId())
+	case enumspb.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_CANCELED:
+		delete(s.children, event.GetChildWorkflowExecutionCanceledEventAttributes().GetInitiatedEventId())
+	case enumspb.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_TIMED_OUT:
+		delete(s.children, event.GetChildWorkflowExecutionTimedOutEventAttributes().GetInitiatedEventId())
+	case enumspb.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_TERMINATED:
+		delete(s.children, event.GetChildWorkflowExecutionTerminatedEventAttributes().GetInitiatedEventId())
+
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_SIGNALED:
+		attr := event.GetWorkflowExecutionSignaledEventAttributes()
+		signal := &receivedSignal{
+			EventID:    event.GetEventId(),
+			EventTime:  eventTime,
+			SignalName: attr.GetSignalName(),
+			Identity:   attr.GetIdentity(),
+		}
+		if attr.GetInput() != nil {
+			signal.Input = formatDecodedPayloads(decodePayloads(attr.GetInput()))
+		}
+		s.signals = append(s.signals, signal)
+	}
+
+	if status := runStatus(event); status != enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING {
+		s.info.Status = status
+		s.info.CloseTime = eventTime
+		s.pendingWorkflowTask = nil
+	}
+}
+
+// describeResponse returns the state in the shape of a describe response
+func (s *workflowState) describeResponse() *workflowservice.DescribeWorkflowExecutionResponse {
+	resp := &workflowservice.DescribeWorkflowExecutionResponse{
+		ExecutionConfig:       s.config,
+		WorkflowExecutionInfo: s.info,
+		PendingWorkflowTask:   s.pendingWorkflowTask,
+	}
+	var activityIDs, childIDs []int64
+	for id := range s.activities {
+		activityIDs = append(activityIDs, id)
+	}
+	for id := range s.children {
+		childIDs = append(childIDs, id)
+	}
+	sort.Slice(activityIDs, func(i, j int) bool { return activityIDs[i] < activityIDs[j] })
+	sort.Slice(childIDs, func(i, j int) bool { return childIDs[i] < childIDs[j] })
+	for _, id := range activityIDs {
+		resp.PendingActivities = append(resp.PendingActivities, s.activities[id])
+	}
+	for _, id := range childIDs {
+		resp.PendingChildren = append(resp.PendingChildren, s.children[id])
+	}
+	return resp
+}
+
+func (s *workflowState) output(c *cli.Context) (*workflowStateOutput, error) {
+	resp := convertDescribeWorkflowExecutionResponse(c, s.describeResponse())
+	encoder := codec.NewJSONPBEncoder()
+	var err error
+	encode := func(pb proto.Message) json.RawMessage {
+		if err != nil {
+			return nil
+		}
+		var b []byte
+		b, err = encoder.Encode(pb)
+		return b
+	}
+
+	out := &workflowStateOutput{
+		EventID:                   s.eventID,
+		WorkflowExecutionInfo:     encode(resp.WorkflowExecutionInfo),
+		SignalsReceived:           s.signals,
+		LastCompletedWorkflowTask: s.lastWorkflowTask,
+	}
+	if resp.ExecutionConfig != nil {
+		out.ExecutionConfig = encode(resp.ExecutionConfig)
+	}
+	if resp.PendingWorkflowTask != nil {
+		out.PendingWorkflowTask = encode(resp.PendingWorkflowTask)
+	}
+	for _, activity := range resp.PendingActivities {
+		out.PendingActivities = append(out.PendingActivities, encode(activity))
+	}
+	for _, child := range resp.PendingChildren {
+		out.PendingChildren = append(out.PendingChildren, encode(child))
+	}
+	timerIDs := make([]string, 0, len(s.timers))
+	for id := range s.timers {
+		timerIDs = append(timerIDs, id)
+	}
+	sort.Slice(timerIDs, func(i, j int) bool {
+		return s.timers[timerIDs[i]].StartedEventID < s.timers[timerIDs[j]].StartedEventID
+	})
+	for _, id := range timerIDs {
+		out.PendingTimers = append(out.PendingTimers, s.timers[id])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode workflow state: %s", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,164 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	historypb "go.temporal.io/api/history/v1"
+	taskqueuepb "go.temporal.io/api/taskqueue/v1"
+	"go.temporal.io/server/common/primitives/timestamp"
+)
+
+var stateStart = time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func stateEvent(eventID int64, eventType enumspb.EventType, attributes interface{}) *historypb.HistoryEvent {
+	event := &historypb.HistoryEvent{
+		EventId:   eventID,
+		EventTime: timestamp.TimePtr(stateStart.Add(time.Duration(eventID) * time.Second)),
+		EventType: eventType,
+	}
+	switch attr := attributes.(type) {
+	case *historypb.WorkflowExecutionStartedEventAttributes:
+		event.Attributes = &historypb.HistoryEvent_WorkflowExecutionStartedEventAttributes{WorkflowExecutionStartedEventAttributes: attr}
+	case *historypb.WorkflowTaskScheduledEventAttributes:
+		event.Attributes = &historypb.HistoryEvent_WorkflowTaskScheduledEventAttributes{WorkflowTaskScheduledEventAttributes: attr}
+	case *historypb.WorkflowTaskStartedEventAttributes:
+		event.Attributes = &historypb.HistoryEvent_WorkflowTaskStartedEventAttributes{WorkflowTaskStartedEventAttributes: attr}
+	case *historypb.WorkflowTaskCompletedEventAttributes:
+		event.Attributes = &historypb.HistoryEvent_WorkflowTaskCompletedEventAttributes{WorkflowTaskCompletedEventAttributes: attr}
+	case *historypb.ActivityTaskScheduledEventAttributes:
+		event.Attributes = &historypb.HistoryEvent_ActivityTaskScheduledEventAttributes{ActivityTaskScheduledEventAttributes: attr}
+	case *historypb.ActivityTaskStartedEventAttributes:
+		event.Attributes = &historypb.HistoryEvent_ActivityTaskStartedEventAttributes{ActivityTaskStartedEventAttributes: attr}
+	case *historypb.ActivityTaskCompletedEventAttributes:
+		event.Attributes = &historypb.HistoryEvent_ActivityTaskCompletedEventAttributes{ActivityTaskCompletedEventAttributes: attr}
+	case *historypb.TimerStartedEventAttributes:
+		event.Attributes = &historypb.HistoryEvent_TimerStartedEventAttributes{TimerStartedEventAttributes: attr}
+	case *historypb.TimerFiredEventAttributes:
+		event.Attributes = &historypb.HistoryEvent_TimerFiredEventAttributes{TimerFiredEventAttributes: attr}
+	case *historypb.StartChildWorkflowExecutionInitiatedEventAttributes:
+		event.Attributes = &historypb.HistoryEvent_StartChildWorkflowExecutionInitiatedEventAttributes{StartChildWorkflowExecutionInitiatedEventAttributes: attr}
+	case *historypb.ChildWorkflowExecutionStartedEventAttributes:
+		event.Attributes = &historypb.HistoryEvent_ChildWorkflowExecutionStartedEventAttributes{ChildWorkflowExecutionStartedEventAttributes: attr}
+	case *historypb.WorkflowExecutionSignaledEventAttributes:
+		event.Attributes = &historypb.HistoryEvent_WorkflowExecutionSignaledEventAttributes{WorkflowExecutionSignaledEventAttributes: attr}
+	case *historypb.WorkflowExecutionCompletedEventAttributes:
+		event.Attributes = &historypb.HistoryEvent_WorkflowExecutionCompletedEventAttributes{WorkflowExecutionCompletedEventAttributes: attr}
+	}
+	return event
+}
+
+func stateHistory() []*historypb.HistoryEvent {
+	return []*historypb.HistoryEvent{
+		stateEvent(1, enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED, &historypb.WorkflowExecutionStartedEventAttributes{
+			WorkflowType: &commonpb.WorkflowType{Name: "Workflow"},
+			TaskQueue:    &taskqueuepb.TaskQueue{Name: "tq"},
+		}),
+		stateEvent(2, enumspb.EVENT_TYPE_WORKFLOW_TASK_SCHEDULED, &historypb.WorkflowTaskScheduledEventAttributes{Attempt: 1}),
+		stateEvent(3, enumspb.EVENT_TYPE_WORKFLOW_TASK_STARTED, &historypb.WorkflowTaskStartedEventAttributes{ScheduledEventId: 2}),
+		stateEvent(4, enumspb.EVENT_TYPE_WORKFLOW_TASK_COMPLETED, &historypb.WorkflowTaskCompletedEventAttributes{
+			ScheduledEventId: 2, StartedEventId: 3, Identity: "worker", BinaryChecksum: "v1",
+		}),
+		stateEvent(5, enumspb.EVENT_TYPE_ACTIVITY_TASK_SCHEDULED, &historypb.ActivityTaskScheduledEventAttributes{
+			ActivityId: "5", ActivityType: &commonpb.ActivityType{Name: "Download"},
+		}),
+		stateEvent(6, enumspb.EVENT_TYPE_TIMER_STARTED, &historypb.TimerStartedEventAttributes{
+			TimerId: "6", StartToFireTimeout: timestamp.DurationPtr(time.Minute),
+		}),
+		stateEvent(7, enumspb.EVENT_TYPE_START_CHILD_WORKFLOW_EXECUTION_INITIATED, &historypb.StartChildWorkflowExecutionInitiatedEventAttributes{
+			WorkflowId: "child", WorkflowType: &commonpb.WorkflowType{Name: "Child"},
+		}),
+		stateEvent(8, enumspb.EVENT_TYPE_ACTIVITY_TASK_STARTED, &historypb.ActivityTaskStartedEventAttributes{
+			ScheduledEventId: 5, Attempt: 2, Identity: "worker",
+		}),
+		stateEvent(9, enumspb.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_STARTED, &historypb.ChildWorkflowExecutionStartedEventAttributes{
+			InitiatedEventId: 7, WorkflowExecution: &commonpb.WorkflowExecution{WorkflowId: "child", RunId: "child-rid"},
+		}),
+		stateEvent(10, enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_SIGNALED, &historypb.WorkflowExecutionSignaledEventAttributes{
+			SignalName: "approve", Identity: "tctl",
+		}),
+		stateEvent(11, enumspb.EVENT_TYPE_ACTIVITY_TASK_COMPLETED, &historypb.ActivityTaskCompletedEventAttributes{ScheduledEventId: 5, StartedEventId: 8}),
+		stateEvent(12, enumspb.EVENT_TYPE_TIMER_FIRED, &historypb.TimerFiredEventAttributes{TimerId: "6", StartedEventId: 6}),
+		stateEvent(13, enumspb.EVENT_TYPE_WORKFLOW_TASK_SCHEDULED, &historypb.WorkflowTaskScheduledEventAttributes{Attempt: 1}),
+	}
+}
+
+func TestWorkflowState(t *testing.T) {
+	events := stateHistory()
+	state := newWorkflowState("wid", "rid")
+	for _, event := range events[:10] {
+		state.apply(event)
+	}
+
+	resp := state.describeResponse()
+	require.Equal(t, enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING, resp.GetWorkflowExecutionInfo().GetStatus())
+	require.Equal(t, int64(10), resp.GetWorkflowExecutionInfo().GetHistoryLength())
+	require.Equal(t, "tq", resp.GetExecutionConfig().GetTaskQueue().GetName())
+	require.Nil(t, resp.GetPendingWorkflowTask())
+	require.Len(t, resp.GetPendingActivities(), 1)
+	require.Equal(t, enumspb.PENDING_ACTIVITY_STATE_STARTED, resp.GetPendingActivities()[0].GetState())
+	require.Equal(t, int32(2), resp.GetPendingActivities()[0].GetAttempt())
+	require.Len(t, resp.GetPendingChildren(), 1)
+	require.Equal(t, "child-rid", resp.GetPendingChildren()[0].GetRunId())
+	require.Len(t, state.timers, 1)
+	require.Equal(t, stateStart.Add(6*time.Second+time.Minute), *state.timers["6"].FireTime)
+	require.Len(t, state.signals, 1)
+	require.Equal(t, "approve", state.signals[0].SignalName)
+	require.Equal(t, int64(4), state.lastWorkflowTask.CompletedEventID)
+	require.Equal(t, "v1", state.lastWorkflowTask.BinaryChecksum)
+
+	for _, event := range events[10:] {
+		state.apply(event)
+	}
+	resp = state.describeResponse()
+	require.Empty(t, resp.GetPendingActivities())
+	require.Empty(t, state.timers)
+	require.Equal(t, enumspb.PENDING_WORKFLOW_TASK_STATE_SCHEDULED, resp.GetPendingWorkflowTask().GetState())
+
+	state.apply(stateEvent(14, enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_COMPLETED, &historypb.WorkflowExecutionCompletedEventAttributes{}))
+	resp = state.describeResponse()
+	require.Equal(t, enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED, resp.GetWorkflowExecutionInfo().GetStatus())
+	require.Nil(t, resp.GetPendingWorkflowTask())
+}
+
+func (s *cliAppSuite) TestDescribeWorkflowAtEvent() {
+	s.sdkClient.On("GetWorkflowHistory", mock.Anything, "wid", "rid", false, enumspb.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT).Return(eventsIterator(stateHistory()...), nil).Once()
+
+	err := s.app.Run([]string{"", "--namespace", cliTestNamespace, "workflow", "describe", "--workflow-id", "wid", "--run-id", "rid", "--at-event", "7"})
+	s.Nil(err)
+	s.sdkClient.AssertExpectations(s.T())
+}
+
+func (s *cliAppSuite) TestDescribeWorkflowAtEvent_BeyondHistory() {
+	s.sdkClient.On("GetWorkflowHistory", mock.Anything, "wid", "rid", false, enumspb.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT).Return(eventsIterator(stateHistory()...), nil).Once()
+
+	errorCode := s.RunWithExitCode([]string{"", "--namespace", cliTestNamespace, "workflow", "describe", "--workflow-id", "wid", "--run-id", "rid", "--at-event", "100"})
+	s.Equal(1, errorCode)
+	s.sdkClient.AssertExpectations(s.T())
+}
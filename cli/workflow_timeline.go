@@ -0,0 +1,519 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/temporalio/tctl-kit/pkg/color"
+	"github.com/urfave/cli/v2"
+	enumspb "go.temporal.io/api/enums/v1"
+	historypb "go.temporal.io/api/history/v1"
+	sdkclient "go.temporal.io/sdk/client"
+	"go.temporal.io/server/common/primitives/timestamp"
+)
+
+const (
+	timelineActivity = "activity"
+	timelineTimer    = "timer"
+	timelineChild    = "child"
+
+	// segmentWaiting is the time from scheduling to the start of the first attempt, waiting for a worker
+	segmentWaiting = "waiting"
+	// segmentRetrying is the time spent in earlier attempts and in backoff between them
+	segmentRetrying = "retrying"
+	segmentRunning  = "running"
+	segmentTimer    = "timer"
+
+	defaultTimelineWidth = 120
+	maxTimelineLabel     = 40
+)
+
+// timelineSpan is an activity, a timer or a child workflow of a workflow run
+type timelineSpan struct {
+	Kind      string
+	Name      string
+	Scheduled time.Time
+	// Started is zero if the span hasn't started yet. Timers start when they are scheduled.
+	Started time.Time
+	// Closed is zero if the span is still open
+	Closed  time.Time
+	Attempt int32
+	Status  string
+}
+
+type timelineSegment struct {
+	Kind  string
+	Start time.Time
+	End   time.Time
+}
+
+type workflowTimeline struct {
+	WorkflowID string
+	RunID      string
+	Start      time.Time
+	// End is the close time of the run, or the current time if it is still running
+	End   time.Time
+	Spans []*timelineSpan
+}
+
+// ShowWorkflowTimeline renders the activities, timers and child workflows of a workflow run as a Gantt chart
+func ShowWorkflowTimeline(c *cli.Context) error {
+	sdkClient, err := getSDKClient(c)
+	if err != nil {
+		return err
+	}
+	wid := c.String(FlagWorkflowID)
+	rid := c.String(FlagRunID)
+
+	ctx, cancel := newIndefiniteContext(c)
+	defer cancel()
+
+	events, err := getHistoryEvents(ctx, sdkClient, wid, rid)
+	if err != nil {
+		return fmt.Errorf("unable to read workflow history: %s", err)
+	}
+	timeline := buildTimeline(events, time.Now())
+	timeline.WorkflowID = wid
+	timeline.RunID = rid
+
+	if file := c.String(FlagOutputFilename); file != "" {
+		f, err := os.Create(file)
+		if err != nil {
+			return fmt.Errorf("unable to create output file: %s", err)
+		}
+		defer f.Close()
+		if err := writeTimelineHTML(f, timeline); err != nil {
+			return fmt.Errorf("unable to write timeline: %s", err)
+		}
+		fmt.Printf("Timeline written to %s\n", file)
+		return nil
+	}
+
+	width := c.Int(FlagWidth)
+	if width <= 0 {
+		width = terminalWidth()
+	}
+	if width <= 0 {
+		width = defaultTimelineWidth
+	}
+	writeTimelineText(os.Stdout, c, timeline, width)
+	return nil
+}
+
+// getHistoryEvents reads the whole history of a workflow run
+func getHistoryEvents(ctx context.Context, sdkClient sdkclient.Client, wid, rid string) ([]*historypb.HistoryEvent, error) {
+	var events []*historypb.HistoryEvent
+	iter := sdkClient.GetWorkflowHistory(ctx, wid, rid, false, enumspb.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
+	for iter.HasNext() {
+		event, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// buildTimeline collects the spans of a workflow run from its history. Spans that are still open end at now.
+func buildTimeline(events []*historypb.HistoryEvent, now time.Time) *workflowTimeline {
+	timeline := &workflowTimeline{}
+	// spans by the Id of the event that scheduled them, which later events refer to
+	spans := make(map[int64]*timelineSpan)
+	add := func(event *historypb.HistoryEvent, span *timelineSpan) {
+		spans[event.GetEventId()] = span
+		timeline.Spans = append(timeline.Spans, span)
+	}
+	start := func(id int64, t time.Time, attempt int32) {
+		if span, ok := spans[id]; ok {
+			span.Started = t
+			span.Attempt = attempt
+			span.Status = "Running"
+		}
+	}
+	closeSpan := func(id int64, t time.Time, status string) {
+		if span, ok := spans[id]; ok {
+			span.Closed = t
+			span.Status = status
+		}
+	}
+
+	var lastEvent *historypb.HistoryEvent
+	for _, event := range events {
+		lastEvent = event
+		t := timestamp.TimeValue(event.GetEventTime())
+		if timeline.Start.IsZero() {
+			timeline.Start = t
+		}
+
+		switch event.GetEventType() {
+		case enumspb.EVENT_TYPE_ACTIVITY_TASK_SCHEDULED:
+			attr := event.GetActivityTaskScheduledEventAttributes()
+			add(event, &timelineSpan{
+				Kind:      timelineActivity,
+				Name:      fmt.Sprintf("%s (%s)", attr.GetActivityType().GetName(), attr.GetActivityId()),
+				Scheduled: t,
+				Status:    "Scheduled",
+			})
+		case enumspb.EVENT_TYPE_ACTIVITY_TASK_STARTED:
+			attr := event.GetActivityTaskStartedEventAttributes()
+			start(attr.GetScheduledEventId(), t, attr.GetAttempt())
+		case enumspb.EVENT_TYPE_ACTIVITY_TASK_COMPLETED:
+			closeSpan(event.GetActivityTaskCompletedEventAttributes().GetScheduledEventId(), t, "Completed")
+		case enumspb.EVENT_TYPE_ACTIVITY_TASK_FAILED:
+			closeSpan(event.GetActivityTaskFailedEventAttributes().GetScheduledEventId(), t, "Failed")
+		case enumspb.EVENT_TYPE_ACTIVITY_TASK_TIMED_OUT:
+			closeSpan(event.GetActivityTaskTimedOutEventAttributes().GetScheduledEventId(), t, "TimedOut")
+		case enumspb.EVENT_TYPE_ACTIVITY_TASK_CANCELED:
+			closeSpan(event.GetActivityTaskCanceledEventAttributes().GetScheduledEventId(), t, "Canceled")
+		case enumspb.EVENT_TYPE_TIMER_STARTED:
+			add(event, &timelineSpan{
+				Kind:      timelineTimer,
+				Name:      "timer " + event.GetTimerStartedEventAttributes().GetTimerId(),
+				Scheduled: t,
+				Started:   t,
+				Status:    "Started",
+			})
+		case enumspb.EVENT_TYPE_TIMER_FIRED:
+			closeSpan(event.GetTimerFiredEventAttributes().GetStartedEventId(), t, "Fired")
+		case enumspb.EVENT_TYPE_TIMER_CANCELED:
+			closeSpan(event.GetTimerCanceledEventAttributes().GetStartedEventId(), t, "Canceled")
+		case enumspb.EVENT_TYPE_START_CHILD_WORKFLOW_EXECUTION_INITIATED:
+			attr := event.GetStartChildWorkflowExecutionInitiatedEventAttributes()
+			add(event, &timelineSpan{
+				Kind:      timelineChild,
+				Name:      fmt.Sprintf("%s (%s)", attr.GetWorkflowType().GetName(), attr.GetWorkflowId()),
+				Scheduled: t,
+				Status:    "Initiated",
+			})
+		case enumspb.EVENT_TYPE_START_CHILD_WORKFLOW_EXECUTION_FAILED:
+			closeSpan(event.GetStartChildWorkflowExecutionFailedEventAttributes().GetInitiatedEventId(), t, "StartFailed")
+		case enumspb.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_STARTED:
+			start(event.GetChildWorkflowExecutionStartedEventAttributes().GetInitiatedEventId(), t, 1)
+		case enumspb.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_COMPLETED:
+			closeSpan(event.GetChildWorkflowExecutionCompletedEventAttributes().GetInitiatedEventId(), t, "Completed")
+		case enumspb.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_FAILED:
+			closeSpan(event.GetChildWorkflowExecutionFailedEventAttributes().GetInitiatedEventId(), t, "Failed")
+		case enumspb.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_CANCELED:
+			closeSpan(event.GetChildWorkflowExecutionCanceledEventAttributes().GetInitiatedEventId(), t, "Canceled")
+		case enumspb.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_TIMED_OUT:
+			closeSpan(event.GetChildWorkflowExecutionTimedOutEventAttributes().GetInitiatedEventId(), t, "TimedOut")
+		case enumspb.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_TERMINATED:
+			closeSpan(event.GetChildWorkflowExecutionTerminatedEventAttributes().GetInitiatedEventId(), t, "Terminated")
+		}
+	}
+
+	timeline.End = now
+	if runStatus(lastEvent) != enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING {
+		timeline.End = timestamp.TimeValue(lastEvent.GetEventTime())
+	}
+
+	return timeline
+}
+
+// segments splits the span into the time spent waiting, retrying and running. Open spans end at end.
+func (s *timelineSpan) segments(end time.Time) []timelineSegment {
+	closed := s.Closed
+	if closed.IsZero() {
+		closed = end
+	}
+
+	switch {
+	case s.Kind == timelineTimer:
+		return []timelineSegment{{Kind: segmentTimer, Start: s.Scheduled, End: closed}}
+	case s.Started.IsZero():
+		return []timelineSegment{{Kind: segmentWaiting, Start: s.Scheduled, End: closed}}
+	}
+
+	// the started event of an activity is only written for its last attempt
+	waiting := segmentWaiting
+	if s.Attempt > 1 {
+		waiting = segmentRetrying
+	}
+	return []timelineSegment{
+		{Kind: waiting, Start: s.Scheduled, End: s.Started},
+		{Kind: segmentRunning, Start: s.Started, End: closed},
+	}
+}
+
+func (s *timelineSpan) label() string {
+	if s.Attempt > 1 {
+		return fmt.Sprintf("%s #%d", s.Name, s.Attempt)
+	}
+	return s.Name
+}
+
+func (s *timelineSpan) duration(end time.Time) time.Duration {
+	closed := s.Closed
+	if closed.IsZero() {
+		closed = end
+	}
+	return roundDuration(closed.Sub(s.Scheduled))
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	if d < time.Second {
+		return d.Round(time.Millisecond)
+	}
+	return d.Round(time.Second)
+}
+
+// activityTime sums the time activities spent in each kind of segment
+func (t *workflowTimeline) activityTime() map[string]time.Duration {
+	total := make(map[string]time.Duration)
+	for _, span := range t.Spans {
+		if span.Kind != timelineActivity {
+			continue
+		}
+		for _, segment := range span.segments(t.End) {
+			total[segment.Kind] += segment.End.Sub(segment.Start)
+		}
+	}
+	return total
+}
+
+var timelineChars = map[string]rune{
+	segmentWaiting:  '░',
+	segmentRetrying: '▒',
+	segmentRunning:  '█',
+	segmentTimer:    '─',
+}
+
+func colorSegment(c *cli.Context, kind string, s string) string {
+	switch kind {
+	case segmentWaiting:
+		return color.Yellow(c, "%s", s)
+	case segmentRetrying:
+		return color.Red(c, "%s", s)
+	case segmentRunning:
+		return color.Green(c, "%s", s)
+	case segmentTimer:
+		return color.Magenta(c, "%s", s)
+	default:
+		return s
+	}
+}
+
+// writeTimelineText renders the timeline as a Gantt chart that fits in width columns
+func writeTimelineText(w io.Writer, c *cli.Context, timeline *workflowTimeline, width int) {
+	total := timeline.End.Sub(timeline.Start)
+	fmt.Fprintf(w, "%s %s, %s\n", color.Magenta(c, "Timeline of"), timeline.WorkflowID, roundDuration(total))
+	fmt.Fprintf(w, "%s running  %s waiting for a worker  %s retrying  %s timer\n\n",
+		colorSegment(c, segmentRunning, string(timelineChars[segmentRunning])),
+		colorSegment(c, segmentWaiting, string(timelineChars[segmentWaiting])),
+		colorSegment(c, segmentRetrying, string(timelineChars[segmentRetrying])),
+		colorSegment(c, segmentTimer, string(timelineChars[segmentTimer])))
+
+	if len(timeline.Spans) == 0 {
+		fmt.Fprintln(w, "No activities, timers or child workflows")
+		return
+	}
+
+	labelWidth := 0
+	for _, span := range timeline.Spans {
+		if l := len([]rune(span.label())); l > labelWidth {
+			labelWidth = l
+		}
+	}
+	if labelWidth > maxTimelineLabel {
+		labelWidth = maxTimelineLabel
+	}
+	const durationWidth = 8
+	barWidth := width - labelWidth - durationWidth - 2
+	if barWidth < 10 {
+		barWidth = 10
+	}
+	if total <= 0 {
+		total = time.Nanosecond
+	}
+	column := func(t time.Time) int {
+		col := int(float64(t.Sub(timeline.Start)) / float64(total) * float64(barWidth))
+		if col < 0 {
+			return 0
+		}
+		if col > barWidth {
+			return barWidth
+		}
+		return col
+	}
+
+	for _, span := range timeline.Spans {
+		cells := make([]string, barWidth)
+		for _, segment := range span.segments(timeline.End) {
+			from, to := column(segment.Start), column(segment.End)
+			if to == from && segment.End.After(segment.Start) && from < barWidth {
+				// keep short segments visible
+				to = from + 1
+			}
+			for i := from; i < to; i++ {
+				cells[i] = segment.Kind
+			}
+		}
+
+		var bar strings.Builder
+		for i := 0; i < barWidth; {
+			j := i
+			for j < barWidth && cells[j] == cells[i] {
+				j++
+			}
+			if cells[i] == "" {
+				bar.WriteString(strings.Repeat(" ", j-i))
+			} else {
+				bar.WriteString(colorSegment(c, cells[i], strings.Repeat(string(timelineChars[cells[i]]), j-i)))
+			}
+			i = j
+		}
+
+		fmt.Fprintf(w, "%-*s %s %*s\n", labelWidth, truncateLabel(span.label(), labelWidth), bar.String(), durationWidth, span.duration(timeline.End))
+	}
+
+	activityTime := timeline.activityTime()
+	fmt.Fprintf(w, "\nActivities: %s waiting, %s retrying, %s running\n",
+		roundDuration(activityTime[segmentWaiting]), roundDuration(activityTime[segmentRetrying]), roundDuration(activityTime[segmentRunning]))
+}
+
+func truncateLabel(label string, width int) string {
+	runes := []rune(label)
+	if len(runes) <= width {
+		return label
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+type timelineHTMLSegment struct {
+	Kind  string
+	Left  float64
+	Width float64
+	Title string
+}
+
+type timelineHTMLRow struct {
+	Label    string
+	Status   string
+	Duration string
+	Segments []timelineHTMLSegment
+}
+
+// timelineHTMLParts are the parts of the timeline chart shared by the timeline and the report pages
+var timelineHTMLParts = template.Must(template.New("parts").Parse(`{{define "timelineStyle"}}
+table.timeline { border-collapse: collapse; width: 100%; }
+table.timeline td { padding: 2px 6px; white-space: nowrap; font-size: 13px; }
+table.timeline td.bar { width: 100%; position: relative; }
+.segment { position: absolute; top: 4px; bottom: 4px; min-width: 1px; }
+.running { background: #43a047; }
+.waiting { background: #fdd835; }
+.retrying { background: #e53935; }
+.timer { background: #8e24aa; opacity: 0.5; }
+.legend span { display: inline-block; width: 12px; height: 12px; margin: 0 4px 0 12px; vertical-align: middle; }
+{{end}}{{define "timelineChart"}}
+<p>Started {{.Start}}, {{.Duration}}. Activities spent {{.Waiting}} waiting for a worker, {{.Retrying}} retrying and {{.Running}} running.</p>
+<p class="legend"><span class="running"></span>running<span class="waiting"></span>waiting for a worker<span class="retrying"></span>retrying<span class="timer"></span>timer</p>
+<table class="timeline">
+{{range .Rows}}<tr>
+<td>{{.Label}}</td>
+<td>{{.Status}}</td>
+<td>{{.Duration}}</td>
+<td class="bar">{{range .Segments}}<div class="segment {{.Kind}}" style="left: {{printf "%.3f" .Left}}%; width: {{printf "%.3f" .Width}}%" title="{{.Title}}"></div>{{end}}</td>
+</tr>
+{{end}}</table>
+{{end}}`))
+
+var timelineHTMLTemplate = template.Must(template.Must(timelineHTMLParts.Clone()).New("timeline").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Timeline of {{.WorkflowID}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+{{template "timelineStyle"}}
+</style>
+</head>
+<body>
+<h2>{{.WorkflowID}}{{if .RunID}} ({{.RunID}}){{end}}</h2>
+{{template "timelineChart" .}}
+</body>
+</html>
+`))
+
+// timelineHTMLData is the data of the timeline chart template
+type timelineHTMLData struct {
+	WorkflowID string
+	RunID      string
+	Start      string
+	Duration   string
+	Waiting    string
+	Retrying   string
+	Running    string
+	Rows       []timelineHTMLRow
+}
+
+// writeTimelineHTML writes the timeline as a self-contained HTML page
+func writeTimelineHTML(w io.Writer, timeline *workflowTimeline) error {
+	return timelineHTMLTemplate.Execute(w, newTimelineHTMLData(timeline))
+}
+
+func newTimelineHTMLData(timeline *workflowTimeline) *timelineHTMLData {
+	total := timeline.End.Sub(timeline.Start)
+	if total <= 0 {
+		total = time.Nanosecond
+	}
+	percent := func(d time.Duration) float64 {
+		return float64(d) / float64(total) * 100
+	}
+
+	var rows []timelineHTMLRow
+	for _, span := range timeline.Spans {
+		row := timelineHTMLRow{
+			Label:    span.label(),
+			Status:   span.Status,
+			Duration: span.duration(timeline.End).String(),
+		}
+		for _, segment := range span.segments(timeline.End) {
+			row.Segments = append(row.Segments, timelineHTMLSegment{
+				Kind:  segment.Kind,
+				Left:  percent(segment.Start.Sub(timeline.Start)),
+				Width: percent(segment.End.Sub(segment.Start)),
+				Title: segment.Kind + " " + roundDuration(segment.End.Sub(segment.Start)).String(),
+			})
+		}
+		rows = append(rows, row)
+	}
+
+	activityTime := timeline.activityTime()
+	return &timelineHTMLData{
+		WorkflowID: timeline.WorkflowID,
+		RunID:      timeline.RunID,
+		Start:      timeline.Start.Format(defaultDateTimeFormat),
+		Duration:   roundDuration(total).String(),
+		Waiting:    roundDuration(activityTime[segmentWaiting]).String(),
+		Retrying:   roundDuration(activityTime[segmentRetrying]).String(),
+		Running:    roundDuration(activityTime[segmentRunning]).String(),
+		Rows:       rows,
+	}
+}
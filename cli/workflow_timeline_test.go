@@ -0,0 +1,147 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	historypb "go.temporal.io/api/history/v1"
+	"go.temporal.io/server/common/primitives/timestamp"
+)
+
+var timelineStart = time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+
+func timelineHistory() []*historypb.HistoryEvent {
+	at := func(seconds int) *time.Time {
+		return timestamp.TimePtr(timelineStart.Add(time.Duration(seconds) * time.Second))
+	}
+	return []*historypb.HistoryEvent{
+		{EventId: 1, EventTime: at(0), EventType: enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED},
+		{EventId: 5, EventTime: at(0), EventType: enumspb.EVENT_TYPE_ACTIVITY_TASK_SCHEDULED, Attributes: &historypb.HistoryEvent_ActivityTaskScheduledEventAttributes{ActivityTaskScheduledEventAttributes: &historypb.ActivityTaskScheduledEventAttributes{
+			ActivityId:   "5",
+			ActivityType: &commonpb.ActivityType{Name: "Charge"},
+		}}},
+		{EventId: 6, EventTime: at(20), EventType: enumspb.EVENT_TYPE_ACTIVITY_TASK_STARTED, Attributes: &historypb.HistoryEvent_ActivityTaskStartedEventAttributes{ActivityTaskStartedEventAttributes: &historypb.ActivityTaskStartedEventAttributes{
+			ScheduledEventId: 5,
+			Attempt:          3,
+		}}},
+		{EventId: 7, EventTime: at(30), EventType: enumspb.EVENT_TYPE_ACTIVITY_TASK_COMPLETED, Attributes: &historypb.HistoryEvent_ActivityTaskCompletedEventAttributes{ActivityTaskCompletedEventAttributes: &historypb.ActivityTaskCompletedEventAttributes{
+			ScheduledEventId: 5,
+			StartedEventId:   6,
+		}}},
+		{EventId: 11, EventTime: at(30), EventType: enumspb.EVENT_TYPE_TIMER_STARTED, Attributes: &historypb.HistoryEvent_TimerStartedEventAttributes{TimerStartedEventAttributes: &historypb.TimerStartedEventAttributes{
+			TimerId: "11",
+		}}},
+		{EventId: 12, EventTime: at(40), EventType: enumspb.EVENT_TYPE_TIMER_FIRED, Attributes: &historypb.HistoryEvent_TimerFiredEventAttributes{TimerFiredEventAttributes: &historypb.TimerFiredEventAttributes{
+			StartedEventId: 11,
+		}}},
+		{EventId: 16, EventTime: at(40), EventType: enumspb.EVENT_TYPE_ACTIVITY_TASK_SCHEDULED, Attributes: &historypb.HistoryEvent_ActivityTaskScheduledEventAttributes{ActivityTaskScheduledEventAttributes: &historypb.ActivityTaskScheduledEventAttributes{
+			ActivityId:   "16",
+			ActivityType: &commonpb.ActivityType{Name: "Ship"},
+		}}},
+	}
+}
+
+func TestBuildTimeline(t *testing.T) {
+	now := timelineStart.Add(50 * time.Second)
+	timeline := buildTimeline(timelineHistory(), now)
+	require.Equal(t, timelineStart, timeline.Start)
+	// the workflow is still running
+	require.Equal(t, now, timeline.End)
+	require.Len(t, timeline.Spans, 3)
+
+	charge := timeline.Spans[0]
+	require.Equal(t, "Charge (5) #3", charge.label())
+	require.Equal(t, "Completed", charge.Status)
+	require.Equal(t, []timelineSegment{
+		{Kind: segmentRetrying, Start: timelineStart, End: timelineStart.Add(20 * time.Second)},
+		{Kind: segmentRunning, Start: timelineStart.Add(20 * time.Second), End: timelineStart.Add(30 * time.Second)},
+	}, charge.segments(timeline.End))
+
+	timer := timeline.Spans[1]
+	require.Equal(t, "Fired", timer.Status)
+	require.Equal(t, 10*time.Second, timer.duration(timeline.End))
+
+	ship := timeline.Spans[2]
+	require.Equal(t, "Scheduled", ship.Status)
+	require.Equal(t, []timelineSegment{
+		{Kind: segmentWaiting, Start: timelineStart.Add(40 * time.Second), End: now},
+	}, ship.segments(timeline.End))
+
+	require.Equal(t, map[string]time.Duration{
+		segmentRetrying: 20 * time.Second,
+		segmentRunning:  10 * time.Second,
+		segmentWaiting:  10 * time.Second,
+	}, timeline.activityTime())
+}
+
+func TestWriteTimelineText(t *testing.T) {
+	timeline := buildTimeline(timelineHistory(), timelineStart.Add(50*time.Second))
+	timeline.WorkflowID = "wid"
+
+	var buf bytes.Buffer
+	writeTimelineText(&buf, nil, timeline, 80)
+	lines := strings.Split(buf.String(), "\n")
+	require.Equal(t, "Timeline of wid, 50s", lines[0])
+	// 13 columns of labels, 57 of bars for 50s and 8 of durations
+	require.Equal(t, "Charge (5) #3 "+strings.Repeat("▒", 22)+strings.Repeat("█", 12)+strings.Repeat(" ", 23)+"      30s", lines[3])
+	require.Equal(t, "timer 11      "+strings.Repeat(" ", 34)+strings.Repeat("─", 11)+strings.Repeat(" ", 12)+"      10s", lines[4])
+	for _, line := range lines[3:6] {
+		require.Len(t, []rune(line), 80)
+	}
+	require.Contains(t, buf.String(), "Activities: 10s waiting, 20s retrying, 10s running")
+}
+
+func TestWriteTimelineHTML(t *testing.T) {
+	timeline := buildTimeline(timelineHistory(), timelineStart.Add(50*time.Second))
+	timeline.WorkflowID = "<wid>"
+
+	var buf bytes.Buffer
+	require.NoError(t, writeTimelineHTML(&buf, timeline))
+	html := buf.String()
+	require.Contains(t, html, "<title>Timeline of &lt;wid&gt;</title>")
+	require.Contains(t, html, `<div class="segment retrying" style="left: 0.000%; width: 40.000%" title="retrying 20s"></div>`)
+	require.Contains(t, html, `<div class="segment running" style="left: 40.000%; width: 20.000%" title="running 10s"></div>`)
+}
+
+func (s *cliAppSuite) TestShowWorkflowTimeline_HTML() {
+	s.sdkClient.On("GetWorkflowHistory", mock.Anything, "wid", "", false, enumspb.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT).Return(eventsIterator(timelineHistory()...)).Once()
+
+	file := filepath.Join(s.T().TempDir(), "timeline.html")
+	err := s.app.Run([]string{"", "--namespace", cliTestNamespace, "workflow", "timeline", "--workflow-id", "wid", "--output-filename", file})
+	s.NoError(err)
+	s.sdkClient.AssertExpectations(s.T())
+
+	html, err := os.ReadFile(file)
+	s.NoError(err)
+	s.Contains(string(html), `class="segment retrying"`)
+}
@@ -0,0 +1,335 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/temporalio/tctl-kit/pkg/color"
+	"github.com/urfave/cli/v2"
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	sdkclient "go.temporal.io/sdk/client"
+	"go.temporal.io/server/common/primitives/timestamp"
+)
+
+const (
+	treeFormatText    = "tree"
+	treeFormatDOT     = "dot"
+	treeFormatMermaid = "mermaid"
+)
+
+var treeFormats = []string{treeFormatText, treeFormatDOT, treeFormatMermaid}
+
+// workflowTreeNode is a workflow execution and the child workflows it started
+type workflowTreeNode struct {
+	WorkflowID string
+	RunID      string
+	Type       string
+	Status     enumspb.WorkflowExecutionStatus
+	StartTime  time.Time
+	CloseTime  time.Time
+	// Error is set if the execution couldn't be described, for example because it was deleted or hasn't started yet
+	Error    string
+	Children []*workflowTreeNode
+}
+
+// ShowWorkflowTree prints the tree of child workflows started by a workflow execution
+func ShowWorkflowTree(c *cli.Context) error {
+	sdkClient, err := getSDKClient(c)
+	if err != nil {
+		return err
+	}
+	wid := c.String(FlagWorkflowID)
+	rid := c.String(FlagRunID)
+	format := c.String(FlagTreeFormat)
+	switch format {
+	case treeFormatText, treeFormatDOT, treeFormatMermaid:
+	default:
+		return fmt.Errorf("invalid format %q, valid values are: %s", format, strings.Join(treeFormats, ", "))
+	}
+
+	ctx, cancel := newIndefiniteContext(c)
+	defer cancel()
+
+	root := buildWorkflowTree(ctx, sdkClient, &commonpb.WorkflowExecution{WorkflowId: wid, RunId: rid}, "", c.Int(FlagDepth))
+	if root.Status == enumspb.WORKFLOW_EXECUTION_STATUS_UNSPECIFIED {
+		return fmt.Errorf("unable to describe workflow: %s", root.Error)
+	}
+
+	switch format {
+	case treeFormatDOT:
+		writeTreeDOT(os.Stdout, root)
+	case treeFormatMermaid:
+		writeTreeMermaid(os.Stdout, root)
+	default:
+		writeTreeText(os.Stdout, c, root, "", "")
+	}
+	return nil
+}
+
+// buildWorkflowTree describes the execution and, up to depth levels down, the child workflows it started.
+// Errors are recorded in the nodes, so that a child that can't be described doesn't hide the rest of the tree.
+func buildWorkflowTree(ctx context.Context, sdkClient sdkclient.Client, execution *commonpb.WorkflowExecution, workflowType string, depth int) *workflowTreeNode {
+	node := &workflowTreeNode{
+		WorkflowID: execution.GetWorkflowId(),
+		RunID:      execution.GetRunId(),
+		Type:       workflowType,
+	}
+
+	resp, err := sdkClient.DescribeWorkflowExecution(ctx, node.WorkflowID, node.RunID)
+	if err != nil {
+		node.Error = err.Error()
+		return node
+	}
+	info := resp.GetWorkflowExecutionInfo()
+	node.RunID = info.GetExecution().GetRunId()
+	node.Type = info.GetType().GetName()
+	node.Status = info.GetStatus()
+	node.StartTime = timestamp.TimeValue(info.GetStartTime())
+	node.CloseTime = timestamp.TimeValue(info.GetCloseTime())
+
+	if depth <= 0 {
+		return node
+	}
+
+	children, err := childExecutions(ctx, sdkClient, node.WorkflowID, node.RunID, resp.GetPendingChildren())
+	if err != nil {
+		node.Error = fmt.Sprintf("unable to read history: %s", err)
+	}
+	for _, child := range children {
+		if child.Execution.GetRunId() == "" {
+			// the child was initiated but hasn't started, describing it by workflow Id alone could return another run
+			node.Children = append(node.Children, &workflowTreeNode{
+				WorkflowID: child.Execution.GetWorkflowId(),
+				Type:       child.Type,
+				Error:      "not started yet",
+			})
+			continue
+		}
+		node.Children = append(node.Children, buildWorkflowTree(ctx, sdkClient, child.Execution, child.Type, depth-1))
+	}
+
+	return node
+}
+
+type childExecution struct {
+	Execution *commonpb.WorkflowExecution
+	Type      string
+}
+
+// childExecutions returns the child workflows started by the run, in the order of its history,
+// followed by the pending children that haven't started yet
+func childExecutions(ctx context.Context, sdkClient sdkclient.Client, wid, rid string, pending []*workflowpb.PendingChildExecutionInfo) ([]*childExecution, error) {
+	var children []*childExecution
+	seen := make(map[string]bool)
+	add := func(wid, rid, workflowType string) {
+		key := wid + "/" + rid
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		children = append(children, &childExecution{
+			Execution: &commonpb.WorkflowExecution{WorkflowId: wid, RunId: rid},
+			Type:      workflowType,
+		})
+	}
+
+	var historyErr error
+	iter := sdkClient.GetWorkflowHistory(ctx, wid, rid, false, enumspb.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
+	for iter.HasNext() {
+		event, err := iter.Next()
+		if err != nil {
+			historyErr = err
+			break
+		}
+		if attr := event.GetChildWorkflowExecutionStartedEventAttributes(); attr != nil {
+			add(attr.GetWorkflowExecution().GetWorkflowId(), attr.GetWorkflowExecution().GetRunId(), attr.GetWorkflowType().GetName())
+		}
+	}
+	for _, child := range pending {
+		add(child.GetWorkflowId(), child.GetRunId(), child.GetWorkflowTypeName())
+	}
+
+	return children, historyErr
+}
+
+func (n *workflowTreeNode) duration() time.Duration {
+	if n.StartTime.IsZero() {
+		return 0
+	}
+	end := n.CloseTime
+	if end.IsZero() {
+		end = time.Now()
+	}
+
+	d := end.Sub(n.StartTime)
+	if d < time.Second {
+		return d.Round(time.Millisecond)
+	}
+	return d.Round(time.Second)
+}
+
+func (n *workflowTreeNode) statusText() string {
+	if n.Error != "" && n.Status == enumspb.WORKFLOW_EXECUTION_STATUS_UNSPECIFIED {
+		return n.Error
+	}
+	return n.Status.String()
+}
+
+// statusClass groups statuses for coloring
+func statusClass(status enumspb.WorkflowExecutionStatus) string {
+	switch status {
+	case enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED, enumspb.WORKFLOW_EXECUTION_STATUS_CONTINUED_AS_NEW:
+		return "completed"
+	case enumspb.WORKFLOW_EXECUTION_STATUS_FAILED, enumspb.WORKFLOW_EXECUTION_STATUS_TIMED_OUT, enumspb.WORKFLOW_EXECUTION_STATUS_TERMINATED:
+		return "failed"
+	case enumspb.WORKFLOW_EXECUTION_STATUS_CANCELED:
+		return "canceled"
+	case enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING:
+		return "running"
+	default:
+		return "unknown"
+	}
+}
+
+// walkTree calls fn for each node in pre-order, with the indexes of the node and of its parent, -1 for the root
+func walkTree(root *workflowTreeNode, fn func(node *workflowTreeNode, id, parentID int)) {
+	next := 0
+	var walk func(node *workflowTreeNode, parentID int)
+	walk = func(node *workflowTreeNode, parentID int) {
+		id := next
+		next++
+		fn(node, id, parentID)
+		for _, child := range node.Children {
+			walk(child, id)
+		}
+	}
+	walk(root, -1)
+}
+
+func writeTreeText(w io.Writer, c *cli.Context, node *workflowTreeNode, prefix, childPrefix string) {
+	status := node.statusText()
+	switch statusClass(node.Status) {
+	case "completed":
+		status = color.Green(c, "%s", status)
+	case "failed":
+		status = color.Red(c, "%s", status)
+	case "canceled", "unknown":
+		status = color.Yellow(c, "%s", status)
+	}
+
+	line := fmt.Sprintf("%s%s %s %s", prefix, node.WorkflowID, node.Type, status)
+	if d := node.duration(); d > 0 {
+		line += " " + d.String()
+	}
+	if node.RunID != "" {
+		line += " (" + node.RunID + ")"
+	}
+	if node.Error != "" && node.Status != enumspb.WORKFLOW_EXECUTION_STATUS_UNSPECIFIED {
+		line += " " + color.Yellow(c, "[%s]", node.Error)
+	}
+	fmt.Fprintln(w, line)
+
+	for i, child := range node.Children {
+		if i == len(node.Children)-1 {
+			writeTreeText(w, c, child, childPrefix+"└── ", childPrefix+"    ")
+		} else {
+			writeTreeText(w, c, child, childPrefix+"├── ", childPrefix+"│   ")
+		}
+	}
+}
+
+// labelLines are the lines of the label of the node in a graph
+func (n *workflowTreeNode) labelLines() []string {
+	lines := []string{n.WorkflowID}
+	if n.Type != "" {
+		lines = append(lines, n.Type)
+	}
+	status := n.statusText()
+	if d := n.duration(); d > 0 {
+		status += " " + d.String()
+	}
+	return append(lines, status)
+}
+
+var dotColors = map[string]string{
+	"completed": "darkgreen",
+	"failed":    "red",
+	"canceled":  "orange",
+	"running":   "blue",
+	"unknown":   "gray",
+}
+
+// writeTreeDOT writes the tree as a Graphviz DOT digraph
+func writeTreeDOT(w io.Writer, root *workflowTreeNode) {
+	fmt.Fprintln(w, "digraph workflows {")
+	fmt.Fprintln(w, "  node [shape=box];")
+	walkTree(root, func(node *workflowTreeNode, id, parentID int) {
+		fmt.Fprintf(w, "  n%d [label=%s, color=%s];\n", id, dotLabel(node.labelLines()), dotColors[statusClass(node.Status)])
+		if parentID >= 0 {
+			fmt.Fprintf(w, "  n%d -> n%d;\n", parentID, id)
+		}
+	})
+	fmt.Fprintln(w, "}")
+}
+
+// dotEscaper escapes the characters that DOT interprets in quoted strings, "\n" is a line break in labels
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", "", "\n", `\n`)
+
+// dotLabel quotes the lines of a DOT label
+func dotLabel(lines []string) string {
+	return `"` + dotEscaper.Replace(strings.Join(lines, "\n")) + `"`
+}
+
+var mermaidClasses = map[string]string{
+	"completed": "stroke:#2e7d32",
+	"failed":    "stroke:#c62828",
+	"canceled":  "stroke:#ef6c00",
+	"running":   "stroke:#1565c0",
+	"unknown":   "stroke:#9e9e9e",
+}
+
+// writeTreeMermaid writes the tree as a Mermaid flowchart
+func writeTreeMermaid(w io.Writer, root *workflowTreeNode) {
+	fmt.Fprintln(w, "graph TD")
+	walkTree(root, func(node *workflowTreeNode, id, parentID int) {
+		lines := node.labelLines()
+		for i, line := range lines {
+			lines[i] = strings.ReplaceAll(line, `"`, "#quot;")
+		}
+		fmt.Fprintf(w, "  n%d[\"%s\"]:::%s\n", id, strings.Join(lines, "<br/>"), statusClass(node.Status))
+		if parentID >= 0 {
+			fmt.Fprintf(w, "  n%d --> n%d\n", parentID, id)
+		}
+	})
+	for _, class := range []string{"completed", "failed", "canceled", "running", "unknown"} {
+		fmt.Fprintf(w, "  classDef %s %s\n", class, mermaidClasses[class])
+	}
+}
@@ -0,0 +1,150 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	historypb "go.temporal.io/api/history/v1"
+	"go.temporal.io/api/serviceerror"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	sdkclient "go.temporal.io/sdk/client"
+	sdkmocks "go.temporal.io/sdk/mocks"
+)
+
+func describeResponse(wid, rid, workflowType string, status enumspb.WorkflowExecutionStatus, pending ...*workflowpb.PendingChildExecutionInfo) *workflowservice.DescribeWorkflowExecutionResponse {
+	return &workflowservice.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: &workflowpb.WorkflowExecutionInfo{
+			Execution: &commonpb.WorkflowExecution{WorkflowId: wid, RunId: rid},
+			Type:      &commonpb.WorkflowType{Name: workflowType},
+			Status:    status,
+		},
+		PendingChildren: pending,
+	}
+}
+
+func eventsIterator(events ...*historypb.HistoryEvent) sdkclient.HistoryEventIterator {
+	iteratorMock := &sdkmocks.HistoryEventIterator{}
+	for _, event := range events {
+		iteratorMock.On("HasNext").Return(true).Once()
+		iteratorMock.On("Next").Return(event, nil).Once()
+	}
+	iteratorMock.On("HasNext").Return(false)
+
+	return iteratorMock
+}
+
+func childStartedEvent(wid, rid, workflowType string) *historypb.HistoryEvent {
+	return &historypb.HistoryEvent{
+		EventType: enumspb.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_STARTED,
+		Attributes: &historypb.HistoryEvent_ChildWorkflowExecutionStartedEventAttributes{ChildWorkflowExecutionStartedEventAttributes: &historypb.ChildWorkflowExecutionStartedEventAttributes{
+			WorkflowExecution: &commonpb.WorkflowExecution{WorkflowId: wid, RunId: rid},
+			WorkflowType:      &commonpb.WorkflowType{Name: workflowType},
+		}},
+	}
+}
+
+func (s *cliAppSuite) TestShowWorkflowTree() {
+	s.sdkClient.On("DescribeWorkflowExecution", mock.Anything, "wid", "").Return(describeResponse("wid", "rid", "Parent", enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING,
+		&workflowpb.PendingChildExecutionInfo{WorkflowId: "child-1", RunId: "child-rid-1", WorkflowTypeName: "Child"},
+		&workflowpb.PendingChildExecutionInfo{WorkflowId: "child-3", WorkflowTypeName: "Child"},
+	), nil).Once()
+	s.sdkClient.On("GetWorkflowHistory", mock.Anything, "wid", "rid", false, enumspb.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT).Return(eventsIterator(
+		childStartedEvent("child-1", "child-rid-1", "Child"),
+		childStartedEvent("child-2", "child-rid-2", "Child"),
+	)).Once()
+	s.sdkClient.On("DescribeWorkflowExecution", mock.Anything, "child-1", "child-rid-1").Return(describeResponse("child-1", "child-rid-1", "Child", enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING), nil).Once()
+	s.sdkClient.On("DescribeWorkflowExecution", mock.Anything, "child-2", "child-rid-2").Return(nil, serviceerror.NewNotFound("not found")).Once()
+
+	// children of children are not walked with a depth of 1
+	err := s.app.Run([]string{"", "--namespace", cliTestNamespace, "workflow", "tree", "--workflow-id", "wid", "--depth", "1"})
+	s.NoError(err)
+	s.sdkClient.AssertExpectations(s.T())
+}
+
+func (s *cliAppSuite) TestShowWorkflowTree_InvalidFormat() {
+	exitCode := s.RunWithExitCode([]string{"", "--namespace", cliTestNamespace, "workflow", "tree", "--workflow-id", "wid", "--format", "svg"})
+	s.Equal(1, exitCode)
+}
+
+func testWorkflowTree() *workflowTreeNode {
+	start := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	return &workflowTreeNode{
+		WorkflowID: "parent",
+		RunID:      "rid",
+		Type:       "Parent",
+		Status:     enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED,
+		StartTime:  start,
+		CloseTime:  start.Add(90 * time.Second),
+		Children: []*workflowTreeNode{
+			{WorkflowID: "child-1", Type: "Child", Status: enumspb.WORKFLOW_EXECUTION_STATUS_FAILED, StartTime: start, CloseTime: start.Add(time.Second)},
+			{WorkflowID: `child-"2"`, Type: "Child", Error: "not started yet"},
+		},
+	}
+}
+
+func TestWriteTreeDOT(t *testing.T) {
+	var buf bytes.Buffer
+	writeTreeDOT(&buf, testWorkflowTree())
+
+	require.Equal(t, `digraph workflows {
+  node [shape=box];
+  n0 [label="parent\nParent\nCompleted 1m30s", color=darkgreen];
+  n1 [label="child-1\nChild\nFailed 1s", color=red];
+  n0 -> n1;
+  n2 [label="child-\"2\"\nChild\nnot started yet", color=gray];
+  n0 -> n2;
+}
+`, buf.String())
+}
+
+func TestDotLabel(t *testing.T) {
+	// only quotes, backslashes and line breaks are escaped, other characters are written as is
+	require.Equal(t, "\"wid\tü\\nline \\\\ \\\"quoted\\\"\\nbroken\\nerror\"",
+		dotLabel([]string{"wid\tü", `line \ "quoted"`, "broken\r\nerror"}))
+}
+
+func TestWriteTreeMermaid(t *testing.T) {
+	var buf bytes.Buffer
+	writeTreeMermaid(&buf, testWorkflowTree())
+
+	require.Equal(t, `graph TD
+  n0["parent<br/>Parent<br/>Completed 1m30s"]:::completed
+  n1["child-1<br/>Child<br/>Failed 1s"]:::failed
+  n0 --> n1
+  n2["child-#quot;2#quot;<br/>Child<br/>not started yet"]:::unknown
+  n0 --> n2
+  classDef completed stroke:#2e7d32
+  classDef failed stroke:#c62828
+  classDef canceled stroke:#ef6c00
+  classDef running stroke:#1565c0
+  classDef unknown stroke:#9e9e9e
+`, buf.String())
+}
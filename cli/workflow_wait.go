@@ -0,0 +1,233 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	enumspb "go.temporal.io/api/enums/v1"
+	historypb "go.temporal.io/api/history/v1"
+	sdkclient "go.temporal.io/sdk/client"
+)
+
+// WaitForWorkflow blocks until the workflow run closes and exits with a code that reflects its status
+func WaitForWorkflow(c *cli.Context) error {
+	sdkClient, err := getSDKClient(c)
+	if err != nil {
+		return err
+	}
+	wid := c.String(FlagWorkflowID)
+	rid := c.String(FlagRunID)
+
+	ctx, cancel := newIndefiniteContext(c)
+	defer cancel()
+	timeout := time.Duration(c.Int(FlagWaitTimeout)) * time.Second
+	if timeout > 0 {
+		var cancelWait context.CancelFunc
+		ctx, cancelWait = context.WithTimeout(ctx, timeout)
+		defer cancelWait()
+	}
+
+	followRuns := c.Bool(FlagFollowRuns)
+	event, runs, err := getLastCloseEvent(ctx, sdkClient, wid, rid, true, followRuns)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return cli.Exit(fmt.Sprintf("workflow %s is still running", wid), exitCodeWaitTimedOut)
+		}
+		return fmt.Errorf("unable to wait for workflow to close: %s", err)
+	}
+	if event == nil {
+		return fmt.Errorf("workflow %s has no close event", wid)
+	}
+
+	if followRuns {
+		printRunChain(runs)
+	}
+	printRunStatus(c, event)
+	return workflowStatusExit(event)
+}
+
+// WorkflowResult prints the result or the failure of a closed workflow run
+func WorkflowResult(c *cli.Context) error {
+	sdkClient, err := getSDKClient(c)
+	if err != nil {
+		return err
+	}
+	wid := c.String(FlagWorkflowID)
+	rid := c.String(FlagRunID)
+
+	ctx, cancel := newContext(c)
+	defer cancel()
+
+	event, _, err := getLastCloseEvent(ctx, sdkClient, wid, rid, false, c.Bool(FlagFollowRuns))
+	if err != nil {
+		return fmt.Errorf("unable to get workflow close event: %s", err)
+	}
+	if event == nil {
+		return fmt.Errorf("workflow %s is still running, use \"workflow wait\" to wait for it to close", wid)
+	}
+
+	switch event.GetEventType() {
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_COMPLETED:
+		fmt.Println(formatDecodedPayloads(decodePayloads(event.GetWorkflowExecutionCompletedEventAttributes().GetResult())))
+	default:
+		printRunStatus(c, event)
+	}
+
+	return workflowStatusExit(event)
+}
+
+// getCloseEvent returns the close event of the workflow run, long polling for it if wait is set.
+// If the run is still open and wait is not set, nil is returned.
+func getCloseEvent(ctx context.Context, sdkClient sdkclient.Client, wid, rid string, wait bool) (*historypb.HistoryEvent, error) {
+	iter := sdkClient.GetWorkflowHistory(ctx, wid, rid, wait, enumspb.HISTORY_EVENT_FILTER_TYPE_CLOSE_EVENT)
+	for iter.HasNext() {
+		event, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		if event != nil {
+			return event, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// getLastCloseEvent returns the close event of the workflow run. If followRuns is set, the runs that continue it
+// are followed and the close event of the last one is returned, along with the outcome of each run.
+func getLastCloseEvent(ctx context.Context, sdkClient sdkclient.Client, wid, rid string, wait, followRuns bool) (*historypb.HistoryEvent, []runOutcome, error) {
+	if followRuns {
+		var err error
+		if rid, err = resolveRunID(ctx, sdkClient, wid, rid); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var runs []runOutcome
+	for {
+		event, err := getCloseEvent(ctx, sdkClient, wid, rid, wait)
+		if err != nil || event == nil {
+			return nil, runs, err
+		}
+		runs = append(runs, runOutcome{RunID: rid, Status: runStatus(event)})
+
+		nextRID := nextRunID(event)
+		if !followRuns || nextRID == "" {
+			return event, runs, nil
+		}
+		rid = nextRID
+	}
+}
+
+// resolveRunID returns the ID of the current run of the workflow if rid is empty, so that each run of a chain can be labeled
+func resolveRunID(ctx context.Context, sdkClient sdkclient.Client, wid, rid string) (string, error) {
+	if rid != "" {
+		return rid, nil
+	}
+
+	resp, err := sdkClient.DescribeWorkflowExecution(ctx, wid, "")
+	if err != nil {
+		return "", fmt.Errorf("unable to get the current run of workflow %s: %s", wid, err)
+	}
+
+	return resp.GetWorkflowExecutionInfo().GetExecution().GetRunId(), nil
+}
+
+type runOutcome struct {
+	RunID  string
+	Status enumspb.WorkflowExecutionStatus
+}
+
+// nextRunID returns the ID of the run that continues a closed run: after a continue-as-new, a retry or for the next cron schedule
+func nextRunID(event *historypb.HistoryEvent) string {
+	switch event.GetEventType() {
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_COMPLETED:
+		return event.GetWorkflowExecutionCompletedEventAttributes().GetNewExecutionRunId()
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_FAILED:
+		return event.GetWorkflowExecutionFailedEventAttributes().GetNewExecutionRunId()
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_TIMED_OUT:
+		return event.GetWorkflowExecutionTimedOutEventAttributes().GetNewExecutionRunId()
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_CONTINUED_AS_NEW:
+		return event.GetWorkflowExecutionContinuedAsNewEventAttributes().GetNewExecutionRunId()
+	default:
+		return ""
+	}
+}
+
+// runStatus returns the status of a run given its last event
+func runStatus(event *historypb.HistoryEvent) enumspb.WorkflowExecutionStatus {
+	switch event.GetEventType() {
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_COMPLETED:
+		return enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_FAILED:
+		return enumspb.WORKFLOW_EXECUTION_STATUS_FAILED
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_TIMED_OUT:
+		return enumspb.WORKFLOW_EXECUTION_STATUS_TIMED_OUT
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_CANCELED:
+		return enumspb.WORKFLOW_EXECUTION_STATUS_CANCELED
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_TERMINATED:
+		return enumspb.WORKFLOW_EXECUTION_STATUS_TERMINATED
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_CONTINUED_AS_NEW:
+		return enumspb.WORKFLOW_EXECUTION_STATUS_CONTINUED_AS_NEW
+	default:
+		return enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING
+	}
+}
+
+// printRunChain prints the outcome of each run of a chain, in the layout of printRunStatus
+func printRunChain(runs []runOutcome) {
+	fmt.Printf("  Runs: %d\n", len(runs))
+	for _, run := range runs {
+		fmt.Printf("    %s %s\n", run.RunID, run.Status)
+	}
+}
+
+// workflowStatusExit returns an error carrying the exit code for the status of a closed workflow run,
+// or nil if the run completed
+func workflowStatusExit(event *historypb.HistoryEvent) error {
+	var code int
+	switch event.GetEventType() {
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_COMPLETED:
+		return nil
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_FAILED:
+		code = exitCodeWorkflowFailed
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_TIMED_OUT:
+		code = exitCodeWorkflowTimedOut
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_CANCELED:
+		code = exitCodeWorkflowCanceled
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_TERMINATED:
+		code = exitCodeWorkflowTerminated
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_CONTINUED_AS_NEW:
+		code = exitCodeWorkflowContinuedAsNew
+	default:
+		return fmt.Errorf("unexpected close event type %s", event.GetEventType())
+	}
+
+	// the status has already been printed
+	return cli.Exit("", code)
+}
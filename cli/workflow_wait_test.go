@@ -0,0 +1,173 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	failurepb "go.temporal.io/api/failure/v1"
+	historypb "go.temporal.io/api/history/v1"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	sdkclient "go.temporal.io/sdk/client"
+	sdkmocks "go.temporal.io/sdk/mocks"
+	"go.temporal.io/server/common/payloads"
+)
+
+func closeEventIterator(event *historypb.HistoryEvent, err error) sdkclient.HistoryEventIterator {
+	iteratorMock := &sdkmocks.HistoryEventIterator{}
+	if event == nil && err == nil {
+		iteratorMock.On("HasNext").Return(false)
+		return iteratorMock
+	}
+
+	iteratorMock.On("HasNext").Return(true).Once()
+	iteratorMock.On("Next").Return(event, err).Once()
+	return iteratorMock
+}
+
+func (s *cliAppSuite) TestWaitForWorkflow() {
+	event := &historypb.HistoryEvent{
+		EventType: enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_FAILED,
+		Attributes: &historypb.HistoryEvent_WorkflowExecutionFailedEventAttributes{WorkflowExecutionFailedEventAttributes: &historypb.WorkflowExecutionFailedEventAttributes{
+			Failure: &failurepb.Failure{
+				Message:     "boom",
+				FailureInfo: &failurepb.Failure_ApplicationFailureInfo{ApplicationFailureInfo: &failurepb.ApplicationFailureInfo{}},
+			},
+		}},
+	}
+	s.sdkClient.On("GetWorkflowHistory", mock.Anything, "wid", "", true, enumspb.HISTORY_EVENT_FILTER_TYPE_CLOSE_EVENT).Return(closeEventIterator(event, nil)).Once()
+
+	exitCode := s.RunWithExitCode([]string{"", "--namespace", cliTestNamespace, "workflow", "wait", "--workflow-id", "wid"})
+	s.Equal(exitCodeWorkflowFailed, exitCode)
+	s.sdkClient.AssertExpectations(s.T())
+}
+
+func (s *cliAppSuite) TestWaitForWorkflow_Timeout() {
+	s.sdkClient.On("GetWorkflowHistory", mock.Anything, "wid", "", true, enumspb.HISTORY_EVENT_FILTER_TYPE_CLOSE_EVENT).
+		Return(func(ctx context.Context, _, _ string, _ bool, _ enumspb.HistoryEventFilterType) sdkclient.HistoryEventIterator {
+			<-ctx.Done()
+			return closeEventIterator(nil, ctx.Err())
+		}).Once()
+
+	exitCode := s.RunWithExitCode([]string{"", "--namespace", cliTestNamespace, "workflow", "wait", "--workflow-id", "wid", "--timeout", "1"})
+	s.Equal(exitCodeWaitTimedOut, exitCode)
+	s.sdkClient.AssertExpectations(s.T())
+}
+
+func (s *cliAppSuite) TestWaitForWorkflow_FollowRuns() {
+	continuedAsNew := &historypb.HistoryEvent{
+		EventType: enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_CONTINUED_AS_NEW,
+		Attributes: &historypb.HistoryEvent_WorkflowExecutionContinuedAsNewEventAttributes{WorkflowExecutionContinuedAsNewEventAttributes: &historypb.WorkflowExecutionContinuedAsNewEventAttributes{
+			NewExecutionRunId: "rid-2",
+		}},
+	}
+	terminated := &historypb.HistoryEvent{
+		EventType: enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_TERMINATED,
+		Attributes: &historypb.HistoryEvent_WorkflowExecutionTerminatedEventAttributes{WorkflowExecutionTerminatedEventAttributes: &historypb.WorkflowExecutionTerminatedEventAttributes{
+			Reason: "test",
+		}},
+	}
+	s.sdkClient.On("DescribeWorkflowExecution", mock.Anything, "wid", "").Return(&workflowservice.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: &workflowpb.WorkflowExecutionInfo{Execution: &commonpb.WorkflowExecution{WorkflowId: "wid", RunId: "rid-1"}},
+	}, nil).Once()
+	s.sdkClient.On("GetWorkflowHistory", mock.Anything, "wid", "rid-1", true, enumspb.HISTORY_EVENT_FILTER_TYPE_CLOSE_EVENT).Return(closeEventIterator(continuedAsNew, nil)).Once()
+	s.sdkClient.On("GetWorkflowHistory", mock.Anything, "wid", "rid-2", true, enumspb.HISTORY_EVENT_FILTER_TYPE_CLOSE_EVENT).Return(closeEventIterator(terminated, nil)).Once()
+
+	exitCode := s.RunWithExitCode([]string{"", "--namespace", cliTestNamespace, "workflow", "wait", "--workflow-id", "wid", "--follow-runs"})
+	s.Equal(exitCodeWorkflowTerminated, exitCode)
+	s.sdkClient.AssertExpectations(s.T())
+}
+
+func (s *cliAppSuite) TestShowHistory_FollowRuns() {
+	s.sdkClient.On("DescribeWorkflowExecution", mock.Anything, "wid", "").Return(&workflowservice.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: &workflowpb.WorkflowExecutionInfo{Execution: &commonpb.WorkflowExecution{WorkflowId: "wid", RunId: "rid"}},
+	}, nil).Once()
+	s.sdkClient.On("GetWorkflowHistory", mock.Anything, "wid", "rid", false, enumspb.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT).Return(historyEventIterator()).Once()
+
+	err := s.app.Run([]string{"", "--namespace", cliTestNamespace, "workflow", "show", "--workflow-id", "wid", "--follow-runs"})
+	s.NoError(err)
+	s.sdkClient.AssertExpectations(s.T())
+}
+
+func (s *cliAppSuite) TestWorkflowResult() {
+	event := &historypb.HistoryEvent{
+		EventType: enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_COMPLETED,
+		Attributes: &historypb.HistoryEvent_WorkflowExecutionCompletedEventAttributes{WorkflowExecutionCompletedEventAttributes: &historypb.WorkflowExecutionCompletedEventAttributes{
+			Result: payloads.EncodeString("done"),
+		}},
+	}
+	s.sdkClient.On("GetWorkflowHistory", mock.Anything, "wid", "rid", false, enumspb.HISTORY_EVENT_FILTER_TYPE_CLOSE_EVENT).Return(closeEventIterator(event, nil)).Once()
+
+	err := s.app.Run([]string{"", "--namespace", cliTestNamespace, "workflow", "result", "--workflow-id", "wid", "--run-id", "rid"})
+	s.NoError(err)
+	s.sdkClient.AssertExpectations(s.T())
+}
+
+func (s *cliAppSuite) TestWorkflowResult_Running() {
+	s.sdkClient.On("GetWorkflowHistory", mock.Anything, "wid", "", false, enumspb.HISTORY_EVENT_FILTER_TYPE_CLOSE_EVENT).Return(closeEventIterator(nil, nil)).Once()
+
+	exitCode := s.RunWithExitCode([]string{"", "--namespace", cliTestNamespace, "workflow", "result", "--workflow-id", "wid"})
+	s.Equal(1, exitCode)
+	s.sdkClient.AssertExpectations(s.T())
+}
+
+func TestNextRunID(t *testing.T) {
+	require.Equal(t, "rid-2", nextRunID(&historypb.HistoryEvent{
+		EventType: enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_FAILED,
+		Attributes: &historypb.HistoryEvent_WorkflowExecutionFailedEventAttributes{WorkflowExecutionFailedEventAttributes: &historypb.WorkflowExecutionFailedEventAttributes{
+			NewExecutionRunId: "rid-2",
+		}},
+	}))
+	require.Equal(t, "rid-3", nextRunID(&historypb.HistoryEvent{
+		EventType: enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_COMPLETED,
+		Attributes: &historypb.HistoryEvent_WorkflowExecutionCompletedEventAttributes{WorkflowExecutionCompletedEventAttributes: &historypb.WorkflowExecutionCompletedEventAttributes{
+			NewExecutionRunId: "rid-3",
+		}},
+	}))
+	require.Empty(t, nextRunID(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_TERMINATED}))
+	require.Empty(t, nextRunID(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED}))
+}
+
+func TestWorkflowStatusExit(t *testing.T) {
+	require.NoError(t, workflowStatusExit(&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_COMPLETED}))
+
+	for eventType, code := range map[enumspb.EventType]int{
+		enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_FAILED:           exitCodeWorkflowFailed,
+		enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_TIMED_OUT:        exitCodeWorkflowTimedOut,
+		enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_CANCELED:         exitCodeWorkflowCanceled,
+		enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_TERMINATED:       exitCodeWorkflowTerminated,
+		enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_CONTINUED_AS_NEW: exitCodeWorkflowContinuedAsNew,
+	} {
+		err := workflowStatusExit(&historypb.HistoryEvent{EventType: eventType})
+		exitErr, ok := err.(cli.ExitCoder)
+		require.True(t, ok, eventType.String())
+		require.Equal(t, code, exitErr.ExitCode(), eventType.String())
+	}
+}
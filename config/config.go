@@ -23,9 +23,228 @@
 package config
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
 	"github.com/temporalio/tctl-kit/pkg/config"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	appName    = "temporalio"
+	configName = "tctl"
+
+	// defaultEnvironment is the environment that exists even if it isn't stored in the config file
+	defaultEnvironment = "local"
 )
 
-func NewTctlConfig() (*config.Config, error) {
-	return config.NewConfig("temporalio", "tctl")
+// Config is the tctl config file with support for managing environments
+type Config struct {
+	*config.Config
+}
+
+func NewTctlConfig() (*Config, error) {
+	cfg, err := config.NewConfig(appName, configName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Config{Config: cfg}, nil
+}
+
+// Dir returns the directory that holds tctl config and other local state
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".config", appName), nil
+}
+
+// Environments returns the sorted names of the environments stored in the config file
+func (c *Config) Environments() ([]string, error) {
+	doc, err := readConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	envs, _ := doc[config.KeyEnvironment].(map[string]interface{})
+	names := make([]string, 0, len(envs)+1)
+	for name := range envs {
+		names = append(names, name)
+	}
+	if _, ok := envs[defaultEnvironment]; !ok {
+		names = append(names, defaultEnvironment)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// HasEnvironment returns whether the environment is stored in the config file
+func (c *Config) HasEnvironment(name string) (bool, error) {
+	envs, err := c.Environments()
+	if err != nil {
+		return false, err
+	}
+
+	for _, env := range envs {
+		if env == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Environment returns the properties of the environment
+func (c *Config) Environment(name string) (map[string]string, error) {
+	doc, err := readConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	envs, _ := doc[config.KeyEnvironment].(map[string]interface{})
+	env, ok := envs[name]
+	if !ok && name != defaultEnvironment {
+		return nil, fmt.Errorf("environment %q does not exist", name)
+	}
+
+	props := make(map[string]string)
+	values, _ := env.(map[string]interface{})
+	for key, val := range values {
+		props[key] = fmt.Sprintf("%v", val)
+	}
+
+	return props, nil
+}
+
+// Set sets the config key and writes the config file, which is only readable by the user as it can hold credentials
+func (c *Config) Set(ctx *cli.Context, key string, value string) error {
+	if err := c.Config.Set(ctx, key, value); err != nil {
+		return err
+	}
+
+	return restrictConfigFile()
+}
+
+// DeleteEnvironment removes the environment from the config file.
+// The config file is rewritten directly, as the underlying config has no support for removing keys.
+func (c *Config) DeleteEnvironment(name string) error {
+	return c.deleteKey(config.KeyEnvironment, name, "environment")
+}
+
+// DeleteAlias removes the command alias from the config file
+func (c *Config) DeleteAlias(name string) error {
+	return c.deleteKey(config.KeyAlias, name, "alias")
+}
+
+// SetAlias creates or replaces a command alias. The config file is updated directly,
+// as setting a nested alias key through the underlying config can drop other aliases.
+func (c *Config) SetAlias(name string, value string) error {
+	doc, err := readConfigFile()
+	if err != nil {
+		return err
+	}
+
+	aliases, _ := doc[config.KeyAlias].(map[string]interface{})
+	if aliases == nil {
+		aliases = make(map[string]interface{})
+		doc[config.KeyAlias] = aliases
+	}
+	aliases[name] = value
+
+	return c.write(doc)
+}
+
+func (c *Config) deleteKey(section string, name string, kind string) error {
+	doc, err := readConfigFile()
+	if err != nil {
+		return err
+	}
+
+	values, _ := doc[section].(map[string]interface{})
+	if _, ok := values[name]; !ok {
+		return fmt.Errorf("%s %q does not exist", kind, name)
+	}
+	delete(values, name)
+
+	return c.write(doc)
+}
+
+// write replaces the config file and reloads the underlying config, so that later reads see the change
+func (c *Config) write(doc map[string]interface{}) error {
+	if err := writeConfigFile(doc); err != nil {
+		return err
+	}
+
+	cfg, err := config.NewConfig(appName, configName)
+	if err != nil {
+		return fmt.Errorf("unable to reload config: %w", err)
+	}
+	c.Config = cfg
+	return nil
+}
+
+func configPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, configName+".yaml"), nil
+}
+
+func readConfigFile() (map[string]interface{}, error) {
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read config file: %w", err)
+	}
+
+	doc := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("unable to parse config file %s: %w", path, err)
+	}
+
+	return doc, nil
+}
+
+func writeConfigFile(doc map[string]interface{}) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("unable to serialize config: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("unable to write config file: %w", err)
+	}
+
+	return restrictConfigFile()
+}
+
+// restrictConfigFile makes the config file only accessible by the user, the file may have been created with wider permissions
+func restrictConfigFile() error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.Chmod(path, 0600); err != nil {
+		return fmt.Errorf("unable to set permissions of config file: %w", err)
+	}
+
+	return nil
 }
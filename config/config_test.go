@@ -0,0 +1,94 @@
+// The MIT License
+//
+// Copyright (c) 2022 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvironments(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cfg, err := NewTctlConfig()
+	require.NoError(t, err)
+
+	envs, err := cfg.Environments()
+	require.NoError(t, err)
+	require.Equal(t, []string{"local"}, envs)
+
+	require.NoError(t, cfg.Set(nil, "environments.staging.address", "staging:7233"))
+	require.NoError(t, cfg.Set(nil, "environments.prod.address", "prod:7233"))
+
+	envs, err = cfg.Environments()
+	require.NoError(t, err)
+	require.Equal(t, []string{"local", "prod", "staging"}, envs)
+
+	props, err := cfg.Environment("staging")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"address": "staging:7233"}, props)
+
+	require.NoError(t, cfg.DeleteEnvironment("staging"))
+	require.Error(t, cfg.DeleteEnvironment("staging"))
+
+	// the config read after deleting doesn't have the environment anymore
+	address, err := cfg.Get(nil, "environments.staging.address")
+	require.NoError(t, err)
+	require.Equal(t, "", address)
+	address, err = cfg.Get(nil, "environments.prod.address")
+	require.NoError(t, err)
+	require.Equal(t, "prod:7233", address)
+
+	path, err := configPath()
+	require.NoError(t, err)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	exists, err := cfg.HasEnvironment("staging")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	_, err = cfg.Environment("staging")
+	require.Error(t, err)
+}
+
+func TestAliases(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cfg, err := NewTctlConfig()
+	require.NoError(t, err)
+
+	require.NoError(t, cfg.SetAlias("wl", "workflow list"))
+	require.NoError(t, cfg.SetAlias("wd", "workflow describe -w $1"))
+	require.NoError(t, cfg.DeleteAlias("wl"))
+	require.Error(t, cfg.DeleteAlias("wl"))
+
+	cfg, err = NewTctlConfig()
+	require.NoError(t, err)
+	aliases, err := cfg.GetAliases()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"wd": "workflow describe -w $1"}, aliases)
+}